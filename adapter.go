@@ -2,7 +2,6 @@ package pgxadapter
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"strings"
 
@@ -38,78 +37,119 @@ func (a *PgxAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex i
 }
 
 // LoadPolicy loads all policy rules from the storage
-func (a *PgxAdapter) LoadPolicyCtx(ctx context.Context, model model.Model) error {
+func (a *PgxAdapter) LoadPolicyCtx(ctx context.Context, model model.Model) (err error) {
+	ctx, op := a.startOperation(ctx, "LoadPolicy", "", 0)
+	defer func() { op.end(err) }()
 
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	var lines [][]string
+	err = a.retry(ctx, true, func() error {
+		var err error
+		lines, err = a.loadPolicyLines(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	op.setRuleCount(len(lines))
+	op.setRowsAffected(int64(len(lines)))
+
+	// Rules the model cannot hold are skipped, as LoadPolicyLine did before values were loaded as arrays
+	for _, line := range lines {
+		_ = persist.LoadPolicyArray(line, model)
+	}
+
+	return nil
+}
+
+// loadPolicyLines reads every stored rule as a policy line
+func (a *PgxAdapter) loadPolicyLines(ctx context.Context) ([][]string, error) {
 	q, args, err := a.psql.
-		Select(selectColumns...).
-		From(a.tableName).
-		OrderBy("id").
+		Select(a.columns...).
+		From(a.relation(nil)).
+		OrderBy(a.idColumn).
 		ToSql()
 
 	if err != nil {
-		return fmt.Errorf("failed to build query: %w", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := a.db.QueryContext(ctx, q, args...)
+	rows, err := a.reader(ctx).Query(ctx, q, args...)
 
 	if err != nil {
-		return fmt.Errorf("failed to query policies: %w", err)
+		return nil, fmt.Errorf("failed to query policies: %w", err)
 	}
 	defer rows.Close() //nolint:errcheck
 
+	var lines [][]string
+	scanner := a.newRuleScanner()
 	for rows.Next() {
-		var ptype string
-		var v0, v1, v2, v3, v4, v5 sql.NullString
-
-		if err := rows.Scan(&ptype, &v0, &v1, &v2, &v3, &v4, &v5); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
-		}
-
-		policyLine := []string{ptype}
-
-		if v0.Valid {
-			policyLine = append(policyLine, v0.String)
-		}
-		if v1.Valid {
-			policyLine = append(policyLine, v1.String)
-		}
-		if v2.Valid {
-			policyLine = append(policyLine, v2.String)
-		}
-		if v3.Valid {
-			policyLine = append(policyLine, v3.String)
-		}
-		if v4.Valid {
-			policyLine = append(policyLine, v4.String)
-		}
-		if v5.Valid {
-			policyLine = append(policyLine, v5.String)
+		if err := rows.Scan(scanner.dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		persist.LoadPolicyLine(strings.Join(policyLine, ", "), model)
+		lines = append(lines, scanner.line())
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
+		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return nil
+	return lines, nil
 }
 
 // SavePolicy saves all policy rules to the storage
-func (a *PgxAdapter) SavePolicyCtx(ctx context.Context, model model.Model) error {
+func (a *PgxAdapter) SavePolicyCtx(ctx context.Context, model model.Model) (err error) {
+	ctx, op := a.startOperation(ctx, "SavePolicy", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationSavePolicy, Model: model}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return err
+	}
+	model = m.Model
+
+	if a.IsFilteredCtx(ctx) {
+		return ErrFilteredSave
+	}
+
+	return a.retry(ctx, true, func() error {
+		return a.savePolicy(ctx, model, op)
+	})
+}
 
+// savePolicy replaces all stored rules with the model's rules in a single transaction
+func (a *PgxAdapter) savePolicy(ctx context.Context, model model.Model, op *operation) error {
 	// Start a transaction
-	tx, err := a.db.BeginTx(ctx, nil)
+	tx, err := a.beginTx(ctx, OpSave)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	tables := a.tables()
+	if err := a.lockForWrite(ctx, tx, tables...); err != nil {
+		return err
 	}
-	defer tx.Rollback() //nolint:errcheck
 
 	// Clear existing policies
-	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
-	truncateSQL := "TRUNCATE TABLE " + quotedTableName
-	if _, err := tx.ExecContext(ctx, truncateSQL); err != nil {
+	quotedTableNames := make([]string, len(tables))
+	for i, table := range tables {
+		quotedTableNames[i] = pgx.Identifier{table}.Sanitize()
+	}
+	truncateSQL := "TRUNCATE TABLE " + strings.Join(quotedTableNames, ", ")
+	if _, err := tx.Exec(ctx, truncateSQL); err != nil {
 		return fmt.Errorf("failed to clear policies: %w", err)
 	}
 
@@ -132,132 +172,234 @@ func (a *PgxAdapter) SavePolicyCtx(ctx context.Context, model model.Model) error
 		}
 	}
 
-	// Batch insert all policies
-	if len(lines) > 0 {
-		insertBuilder := a.psql.Insert(a.tableName).
-			Columns(insertColumns...)
-
-		for i, line := range lines {
-			vals := make([]any, 7)
-			vals[0] = ptypes[i]
-
-			for j := range 6 {
-				if j < len(line) && line[j] != "" {
-					vals[j+1] = line[j]
-				} else {
-					vals[j+1] = nil
-				}
-			}
-
-			insertBuilder = insertBuilder.Values(vals...)
+	for i, line := range lines {
+		lines[i] = a.normalizeRule(ptypes[i], line)
+		if err := a.checkRule(ptypes[i], lines[i]); err != nil {
+			return err
 		}
+	}
 
-		sqlStr, args, err := insertBuilder.ToSql()
-		if err != nil {
-			return fmt.Errorf("failed to build insert query: %w", err)
-		}
+	// Batch insert all policies, table by table
+	values := make(map[string][][]any)
+	for i, line := range lines {
+		table := a.tableFor(ptypes[i])
+		values[table] = append(values[table], a.ruleValues(ptypes[i], line))
+	}
 
-		if _, err := tx.ExecContext(ctx, sqlStr, args...); err != nil {
+	for _, table := range tables {
+		if err := a.insertPolicies(ctx, tx, table, values[table]); err != nil {
 			return fmt.Errorf("failed to insert policies: %w", err)
 		}
 	}
 
 	// Commit transaction
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	op.setRuleCount(len(lines))
+	op.setRowsAffected(int64(len(lines)))
 
 	return nil
 }
 
 // AddPolicy adds a policy rule to the storage
-func (a *PgxAdapter) AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+func (a *PgxAdapter) AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) (err error) {
+	ctx, op := a.startOperation(ctx, "AddPolicy", ptype, 1)
+	defer func() { op.end(err) }()
 
-	vals := make([]any, 7)
-	vals[0] = ptype
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
 
-	for i := range 6 {
-		if i < len(rule) && rule[i] != "" {
-			vals[i+1] = rule[i]
-		} else {
-			vals[i+1] = nil
-		}
+	m := &Mutation{Kind: MutationAddPolicies, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return err
+	}
+	ptype = m.Ptype
+	if rule, err = m.singleRule(); err != nil {
+		return err
 	}
+	rule = a.normalizeRule(ptype, rule)
 
-	sqlStr, args, err := a.psql.
-		Insert(a.tableName).
-		Columns(insertColumns...).
-		Values(vals...).
-		Suffix("ON CONFLICT DO NOTHING").
-		ToSql()
+	if err := a.checkRule(ptype, rule); err != nil {
+		return err
+	}
 
+	return a.retry(ctx, !a.strict, func() error {
+		rowsAffected, err := a.addPolicy(ctx, ptype, rule)
+		op.setRowsAffected(rowsAffected)
+		return err
+	})
+}
+
+// addPolicy inserts a single rule, ignoring duplicates unless in strict mode
+func (a *PgxAdapter) addPolicy(ctx context.Context, ptype string, rule []string) (int64, error) {
+	args := a.ruleValues(ptype, rule)
+
+	table := a.tableFor(ptype)
+	sqlStr, err := a.stmts.get(statementKey{table: table, shape: shapeAddPolicy}, func() (string, error) {
+		sqlStr, _, err := a.psql.
+			Insert(table).
+			Columns(a.columns...).
+			Values(args...).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+		return sqlStr, err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to build insert query: %w", err)
+		return 0, fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = a.db.ExecContext(ctx, sqlStr, args...)
+	tag, err := a.db.Exec(ctx, sqlStr, args...)
 	if err != nil {
-		return fmt.Errorf("failed to add policy: %w", err)
+		return 0, newPolicyError(fmt.Errorf("failed to add policy: %w", err), ptype, rule)
 	}
 
-	return nil
+	if a.strict && tag.RowsAffected() == 0 {
+		return 0, newPolicyError(ErrPolicyExists, ptype, rule)
+	}
+
+	return tag.RowsAffected(), nil
 }
 
 // RemovePolicy removes a policy rule from the storage
-func (a *PgxAdapter) RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+func (a *PgxAdapter) RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) (err error) {
+	ctx, op := a.startOperation(ctx, "RemovePolicy", ptype, 1)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationRemovePolicies, Sec: sec, Ptype: ptype, Rules: [][]string{rule}}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return err
+	}
+	ptype = m.Ptype
+	if rule, err = m.singleRule(); err != nil {
+		return err
+	}
+	rule = a.normalizeRule(ptype, rule)
+
+	if err := a.checkRuleLength(rule); err != nil {
+		return newPolicyError(err, ptype, rule)
+	}
 
-	deleteBuilder := a.psql.Delete(a.tableName).Where(sq.Eq{"ptype": ptype})
+	return a.retry(ctx, !a.strict, func() error {
+		rowsAffected, err := a.removePolicy(ctx, ptype, rule)
+		op.setRowsAffected(rowsAffected)
+		return err
+	})
+}
 
-	// Add conditions for each rule value
+// removePolicy deletes the rows matching the rule's non-empty fields
+func (a *PgxAdapter) removePolicy(ctx context.Context, ptype string, rule []string) (int64, error) {
+	var fields uint64
+	args := []any{ptype}
 	for i, r := range rule {
-		col := colParams[i]
 		if r != "" {
-			deleteBuilder = deleteBuilder.Where(sq.Eq{col: r})
+			fields |= 1 << i
+			args = append(args, a.fieldEqArgs(r)...)
 		}
 	}
 
-	sqlStr, args, err := deleteBuilder.ToSql()
+	table := a.tableFor(ptype)
+	build := func() (string, error) {
+		deleteBuilder := a.psql.Delete(table).Where(sq.Eq{a.columns[0]: ptype})
+
+		// Add conditions for each rule value
+		for i, r := range rule {
+			if r != "" {
+				deleteBuilder = deleteBuilder.Where(a.fieldEq(i, r))
+			}
+		}
+
+		sqlStr, _, err := deleteBuilder.ToSql()
+		return sqlStr, err
+	}
+
+	var sqlStr string
+	var err error
+	if len(rule) > maxStatementFields {
+		sqlStr, err = build()
+	} else {
+		sqlStr, err = a.stmts.get(statementKey{table: table, shape: shapeRemovePolicy, fields: fields}, build)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to build delete query: %w", err)
+		return 0, fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	_, err = a.db.ExecContext(ctx, sqlStr, args...)
+	tag, err := a.db.Exec(ctx, sqlStr, args...)
 	if err != nil {
-		return fmt.Errorf("failed to remove policy: %w", err)
+		return 0, newPolicyError(fmt.Errorf("failed to remove policy: %w", err), ptype, rule)
 	}
 
-	return nil
+	if a.strict && tag.RowsAffected() == 0 {
+		return 0, newPolicyError(ErrPolicyNotFound, ptype, rule)
+	}
+
+	return tag.RowsAffected(), nil
 }
 
 // RemoveFilteredPolicy removes policy rules that match the filter from the storage
-func (a *PgxAdapter) RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+func (a *PgxAdapter) RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) (err error) {
+	ctx, op := a.startOperation(ctx, "RemoveFilteredPolicy", ptype, 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationRemoveFilteredPolicy, Sec: sec, Ptype: ptype, FieldIndex: fieldIndex, FieldValues: fieldValues}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return err
+	}
+	ptype, fieldIndex, fieldValues = m.Ptype, m.FieldIndex, m.FieldValues
+	fieldValues = a.normalizeFieldValues(ptype, fieldIndex, fieldValues)
 
-	if fieldIndex < 0 || fieldIndex > 5 {
-		return fmt.Errorf("invalid field index: %d", fieldIndex)
+	if err := a.checkFieldIndex(ptype, fieldIndex, fieldValues); err != nil {
+		return err
 	}
 
-	deleteBuilder := a.psql.Delete(a.tableName).Where(sq.Eq{"ptype": ptype})
+	return a.retry(ctx, true, func() error {
+		rowsAffected, err := a.removeFilteredPolicy(ctx, ptype, fieldIndex, fieldValues)
+		op.setRowsAffected(rowsAffected)
+		return err
+	})
+}
+
+// removeFilteredPolicy deletes the rows matching fieldValues starting at fieldIndex
+func (a *PgxAdapter) removeFilteredPolicy(ctx context.Context, ptype string, fieldIndex int, fieldValues []string) (int64, error) {
+	deleteBuilder := a.psql.Delete(a.tableFor(ptype)).Where(sq.Eq{a.columns[0]: ptype})
 
 	// Add conditions for filtered values
 	for i := range fieldValues {
-		if i+fieldIndex > 5 {
+		if i+fieldIndex >= a.maxFields() {
 			break
 		}
-		col := colParams[i+fieldIndex]
 		if fieldValues[i] != "" {
-			deleteBuilder = deleteBuilder.Where(sq.Eq{col: fieldValues[i]})
+			deleteBuilder = deleteBuilder.Where(a.fieldEq(i+fieldIndex, fieldValues[i]))
 		}
 	}
 
 	sqlStr, args, err := deleteBuilder.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build delete query: %w", err)
+		return 0, fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	_, err = a.db.ExecContext(ctx, sqlStr, args...)
+	tag, err := a.db.Exec(ctx, sqlStr, args...)
 	if err != nil {
-		return fmt.Errorf("failed to remove filtered policies: %w", err)
+		return 0, &FilterError{Ptype: ptype, FieldIndex: fieldIndex, FieldValues: fieldValues, Err: fmt.Errorf("failed to remove filtered policies: %w", err)}
 	}
 
-	return nil
+	return tag.RowsAffected(), nil
 }
@@ -503,7 +503,7 @@ func TestRemoveFilteredPolicy(t *testing.T) {
 			fieldIndex:    7,
 			fieldValues:   []string{"alice"},
 			wantErr:       true,
-			errMsg:        "invalid field index",
+			errMsg:        "field index out of range",
 			expectedCount: 0,
 		},
 	}
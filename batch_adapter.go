@@ -3,10 +3,50 @@ package pgxadapter
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// RuleOutcome describes what a batch mutation did with a single rule.
+type RuleOutcome int
+
+const (
+	// RuleInserted means the rule was added to the storage.
+	RuleInserted RuleOutcome = iota + 1
+	// RuleSkippedDuplicate means the rule was already stored and was left unchanged.
+	RuleSkippedDuplicate
+	// RuleRemoved means at least one stored rule matched and was deleted.
+	RuleRemoved
+	// RuleNotFound means no stored rule matched.
+	RuleNotFound
+)
+
+// String returns the outcome name.
+func (o RuleOutcome) String() string {
+	switch o {
+	case RuleInserted:
+		return "inserted"
+	case RuleSkippedDuplicate:
+		return "skipped-duplicate"
+	case RuleRemoved:
+		return "removed"
+	case RuleNotFound:
+		return "not-found"
+	default:
+		return fmt.Sprintf("RuleOutcome(%d)", int(o))
+	}
+}
+
+// RuleResult reports the outcome of a batch mutation for one input rule.
+type RuleResult struct {
+	Rule    []string
+	Outcome RuleOutcome
+}
+
 // AddPolicies adds policy rules to the storage
 func (a *PgxAdapter) AddPolicies(sec string, ptype string, rules [][]string) error {
 	return a.AddPoliciesCtx(context.Background(), sec, ptype, rules)
@@ -17,29 +57,143 @@ func (a *PgxAdapter) RemovePolicies(sec string, ptype string, rules [][]string)
 	return a.RemovePoliciesCtx(context.Background(), sec, ptype, rules)
 }
 
+// AddPoliciesWithResults adds policy rules to the storage and reports the outcome for each rule
+func (a *PgxAdapter) AddPoliciesWithResults(sec string, ptype string, rules [][]string) ([]RuleResult, error) {
+	return a.AddPoliciesWithResultsCtx(context.Background(), sec, ptype, rules)
+}
+
+// RemovePoliciesWithResults removes policy rules from the storage and reports the outcome for each rule
+func (a *PgxAdapter) RemovePoliciesWithResults(sec string, ptype string, rules [][]string) ([]RuleResult, error) {
+	return a.RemovePoliciesWithResultsCtx(context.Background(), sec, ptype, rules)
+}
+
 // AddPolicies adds policy rules to the storage
 func (a *PgxAdapter) AddPoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	_, err := a.addPoliciesWithResults(ctx, sec, ptype, rules, !a.strict)
+	return err
+}
+
+// RemovePolicies removes policy rules from the storage
+func (a *PgxAdapter) RemovePoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	_, err := a.removePoliciesWithResults(ctx, sec, ptype, rules, !a.strict)
+	return err
+}
+
+// AddPoliciesWithResultsCtx adds policy rules to the storage and reports, in input order,
+// whether each rule was inserted or skipped because it already existed.
+// Batches larger than the batch size are inserted in chunks inside a single transaction.
+// In strict mode nothing is added and ErrPolicyExists is returned if any rule is skipped.
+func (a *PgxAdapter) AddPoliciesWithResultsCtx(ctx context.Context, sec string, ptype string, rules [][]string) ([]RuleResult, error) {
+	// A retry after the connection dropped mid-request would report rules the lost attempt
+	// inserted as skipped, so only attempts the server is known not to have applied are retried
+	return a.addPoliciesWithResults(ctx, sec, ptype, rules, false)
+}
+
+// addPoliciesWithResults implements AddPoliciesWithResultsCtx, retrying after a dropped
+// connection only if idempotent
+func (a *PgxAdapter) addPoliciesWithResults(ctx context.Context, sec string, ptype string, rules [][]string, idempotent bool) (results []RuleResult, err error) {
+	ctx, op := a.startOperation(ctx, "AddPolicies", ptype, len(rules))
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationAddPolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return nil, err
+	}
+	ptype, rules = m.Ptype, m.Rules
+	rules = a.normalizeRules(ptype, rules)
+
 	if len(rules) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	insertBuilder := a.psql.Insert(a.tableName).
-		Columns(insertColumns...).
-		Suffix("ON CONFLICT DO NOTHING")
+	if err := a.checkRules(ptype, rules); err != nil {
+		return nil, err
+	}
 
-	for _, rule := range rules {
-		vals := make([]any, 7)
-		vals[0] = ptype
-
-		for i := range 6 {
-			if i < len(rule) && rule[i] != "" {
-				vals[i+1] = rule[i]
-			} else {
-				vals[i+1] = nil
+	err = a.retry(ctx, idempotent, func() error {
+		var err error
+		if a.strict || len(rules) > a.batchSize {
+			results, err = a.addPoliciesTx(ctx, ptype, rules)
+		} else {
+			results, err = a.addPolicies(ctx, a.db, ptype, rules)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	op.setRowsAffected(changedRules(results))
+
+	return results, nil
+}
+
+// addPoliciesTx inserts rules inside a transaction so the batch is atomic across chunks
+// and, in strict mode, a duplicate can roll back the whole batch
+func (a *PgxAdapter) addPoliciesTx(ctx context.Context, ptype string, rules [][]string) ([]RuleResult, error) {
+	tx, err := a.beginTx(ctx, OpAdd)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	results, err := a.addPolicies(ctx, tx, ptype, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.strict {
+		for i, result := range results {
+			if result.Outcome == RuleSkippedDuplicate {
+				return nil, newBatchPolicyError(ErrPolicyExists, ptype, result.Rule, i)
 			}
 		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// addPolicies inserts rules with one statement per chunk and matches the returned rows back to the input.
+func (a *PgxAdapter) addPolicies(ctx context.Context, q pgxConn, ptype string, rules [][]string) ([]RuleResult, error) {
+	// Count the inserted rows per rule so in-batch duplicates are reported as skipped
+	inserted := make(map[string]int)
+	for chunk := range slices.Chunk(rules, a.batchSize) {
+		if err := a.addPoliciesChunk(ctx, q, ptype, chunk, inserted); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]RuleResult, len(rules))
+	for i, rule := range rules {
+		key := a.ruleKey(rule)
+		results[i] = RuleResult{Rule: rule, Outcome: RuleSkippedDuplicate}
+		if inserted[key] > 0 {
+			inserted[key]--
+			results[i].Outcome = RuleInserted
+		}
+	}
 
-		insertBuilder = insertBuilder.Values(vals...)
+	return results, nil
+}
+
+// addPoliciesChunk inserts a single chunk of rules, counting the inserted rows by rule key
+func (a *PgxAdapter) addPoliciesChunk(ctx context.Context, q pgxConn, ptype string, rules [][]string, inserted map[string]int) error {
+	insertBuilder := a.psql.Insert(a.tableFor(ptype)).
+		Columns(a.columns...).
+		Suffix("ON CONFLICT DO NOTHING RETURNING " + strings.Join(a.columns, ", "))
+
+	for _, rule := range rules {
+		insertBuilder = insertBuilder.Values(a.ruleValues(ptype, rule)...)
 	}
 
 	sqlStr, args, err := insertBuilder.ToSql()
@@ -47,55 +201,162 @@ func (a *PgxAdapter) AddPoliciesCtx(ctx context.Context, sec string, ptype strin
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	_, err = a.db.ExecContext(ctx, sqlStr, args...)
-
+	rows, err := q.Query(ctx, sqlStr, args...)
 	if err != nil {
 		return fmt.Errorf("failed to add policies: %w", err)
 	}
+	defer rows.Close() //nolint:errcheck
+
+	scanner := a.newRuleScanner()
+	for rows.Next() {
+		if err := rows.Scan(scanner.dest...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		inserted[scanner.key()]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to add policies: %w", err)
+	}
 
 	return nil
 }
 
-// RemovePolicies removes policy rules from the storage
-func (a *PgxAdapter) RemovePoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+// insertPolicies inserts rows of ruleValues into table with one statement per chunk of at most the batch size
+func (a *PgxAdapter) insertPolicies(ctx context.Context, q pgxConn, table string, rows [][]any) error {
+	for chunk := range slices.Chunk(rows, a.batchSize) {
+		insertBuilder := a.psql.Insert(table).Columns(a.columns...)
+		for _, row := range chunk {
+			insertBuilder = insertBuilder.Values(row...)
+		}
+
+		sqlStr, args, err := insertBuilder.ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build insert query: %w", err)
+		}
+
+		if _, err := q.Exec(ctx, sqlStr, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemovePoliciesWithResultsCtx removes policy rules from the storage within a transaction
+// and reports, in input order, whether each rule was removed or not found.
+// In strict mode nothing is removed and ErrPolicyNotFound is returned if any rule is not found.
+func (a *PgxAdapter) RemovePoliciesWithResultsCtx(ctx context.Context, sec string, ptype string, rules [][]string) ([]RuleResult, error) {
+	// A retry after the connection dropped mid-request would report rules the lost attempt
+	// removed as not found, so only attempts the server is known not to have applied are retried
+	return a.removePoliciesWithResults(ctx, sec, ptype, rules, false)
+}
+
+// removePoliciesWithResults implements RemovePoliciesWithResultsCtx, retrying after a dropped
+// connection only if idempotent
+func (a *PgxAdapter) removePoliciesWithResults(ctx context.Context, sec string, ptype string, rules [][]string, idempotent bool) (results []RuleResult, err error) {
+	ctx, op := a.startOperation(ctx, "RemovePolicies", ptype, len(rules))
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationRemovePolicies, Sec: sec, Ptype: ptype, Rules: rules}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return nil, err
+	}
+	ptype, rules = m.Ptype, m.Rules
+	rules = a.normalizeRules(ptype, rules)
+
 	if len(rules) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Start a transaction
-	tx, err := a.db.BeginTx(ctx, nil)
+	for i, rule := range rules {
+		if err := a.checkRuleLength(rule); err != nil {
+			return nil, newBatchPolicyError(err, ptype, rule, i)
+		}
+	}
+
+	err = a.retry(ctx, idempotent, func() error {
+		var err error
+		results, err = a.removePolicies(ctx, ptype, rules)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
 	}
-	defer tx.Rollback()
+	op.setRowsAffected(changedRules(results))
+
+	return results, nil
+}
 
+// removePolicies deletes the rules inside a single transaction, pipelining one statement per rule
+// so wildcard fields and per-rule outcomes are preserved without a round trip per rule
+func (a *PgxAdapter) removePolicies(ctx context.Context, ptype string, rules [][]string) ([]RuleResult, error) {
+	batch := &pgx.Batch{}
 	for _, rule := range rules {
-		deleteBuilder := a.psql.Delete(a.tableName).Where(sq.Eq{"ptype": ptype})
+		deleteBuilder := a.psql.Delete(a.tableFor(ptype)).Where(sq.Eq{a.columns[0]: ptype})
 
 		// Add conditions for each rule value
-		for i := range 6 {
-			col := colParams[i]
-			if i < len(rule) && rule[i] != "" {
-				deleteBuilder = deleteBuilder.Where(sq.Eq{col: rule[i]})
+		for i, r := range rule {
+			if r != "" {
+				deleteBuilder = deleteBuilder.Where(a.fieldEq(i, r))
 			}
 		}
 
 		sqlStr, args, err := deleteBuilder.ToSql()
 		if err != nil {
-			return fmt.Errorf("failed to build delete query: %w", err)
+			return nil, fmt.Errorf("failed to build delete query: %w", err)
 		}
+		batch.Queue(sqlStr, args...)
+	}
 
-		_, err = tx.ExecContext(ctx, sqlStr, args...)
+	// Start a transaction
+	tx, err := a.beginTx(ctx, OpRemove)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	results := make([]RuleResult, len(rules))
+	err = execBatch(ctx, tx, batch, func(idx int, tag pgconn.CommandTag, err error) error {
+		rule := rules[idx]
 		if err != nil {
-			return fmt.Errorf("failed to remove policy: %w", err)
+			return newBatchPolicyError(fmt.Errorf("failed to remove policy: %w", err), ptype, rule, idx)
 		}
 
+		results[idx] = RuleResult{Rule: rule, Outcome: RuleNotFound}
+		if tag.RowsAffected() > 0 {
+			results[idx].Outcome = RuleRemoved
+		} else if a.strict {
+			return newBatchPolicyError(ErrPolicyNotFound, ptype, rule, idx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	return results, nil
+}
+
+// changedRules counts the results whose rule was inserted or removed
+func changedRules(results []RuleResult) int64 {
+	var n int64
+	for _, result := range results {
+		if result.Outcome == RuleInserted || result.Outcome == RuleRemoved {
+			n++
+		}
+	}
+	return n
 }
@@ -3,9 +3,11 @@ package pgxadapter_test
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
 )
 
 func TestAddPolicies(t *testing.T) {
@@ -319,3 +321,202 @@ func TestAddPoliciesWithPartialDuplicates(t *testing.T) {
 		t.Errorf("AddPoliciesCtx() expected 3 policies, but found %d", count)
 	}
 }
+
+func TestAddPoliciesWithResults(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_add_batch_with_results"
+	adapter, _ := setupTestAdapter(t, tableName)
+
+	if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+		t.Fatalf("Failed to setup policy: %v", err)
+	}
+
+	rules := [][]string{
+		{"alice", "data1", "read"},
+		{"bob", "data2", "write"},
+		{"bob", "data2", "write"},
+		{"charlie", "", "read"},
+	}
+	expected := []pgxadapter.RuleOutcome{
+		pgxadapter.RuleSkippedDuplicate,
+		pgxadapter.RuleInserted,
+		pgxadapter.RuleSkippedDuplicate,
+		pgxadapter.RuleInserted,
+	}
+
+	results, err := adapter.AddPoliciesWithResultsCtx(ctx, "p", "p", rules)
+	if err != nil {
+		t.Fatalf("AddPoliciesWithResultsCtx() unexpected error: %v", err)
+	}
+
+	if len(results) != len(rules) {
+		t.Fatalf("AddPoliciesWithResultsCtx() returned %d results, want %d", len(results), len(rules))
+	}
+
+	for i, result := range results {
+		if !slices.Equal(result.Rule, rules[i]) {
+			t.Errorf("AddPoliciesWithResultsCtx() result %d rule = %v, want %v", i, result.Rule, rules[i])
+		}
+		if result.Outcome != expected[i] {
+			t.Errorf("AddPoliciesWithResultsCtx() result %d outcome = %v, want %v", i, result.Outcome, expected[i])
+		}
+	}
+}
+
+func TestRemovePoliciesWithResults(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_remove_batch_with_results"
+	adapter, _ := setupTestAdapter(t, tableName)
+
+	for _, rule := range [][]string{
+		{"alice", "data1", "read"},
+		{"bob", "data2", "write"},
+	} {
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+	}
+
+	rules := [][]string{
+		{"alice", "data1", "read"},
+		{"charlie", "data3", "read"},
+		{"alice", "data1", "read"},
+		{"bob", "data2", "write"},
+	}
+	expected := []pgxadapter.RuleOutcome{
+		pgxadapter.RuleRemoved,
+		pgxadapter.RuleNotFound,
+		pgxadapter.RuleNotFound,
+		pgxadapter.RuleRemoved,
+	}
+
+	results, err := adapter.RemovePoliciesWithResultsCtx(ctx, "p", "p", rules)
+	if err != nil {
+		t.Fatalf("RemovePoliciesWithResultsCtx() unexpected error: %v", err)
+	}
+
+	if len(results) != len(rules) {
+		t.Fatalf("RemovePoliciesWithResultsCtx() returned %d results, want %d", len(results), len(rules))
+	}
+
+	for i, result := range results {
+		if result.Outcome != expected[i] {
+			t.Errorf("RemovePoliciesWithResultsCtx() result %d outcome = %v, want %v", i, result.Outcome, expected[i])
+		}
+	}
+}
+
+func TestRemovePoliciesLargeBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_remove_large_batch"
+	adapter, db := setupTestAdapter(t, tableName)
+
+	rules := make([][]string, 5000)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+	}
+	if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules[:4000]); err != nil {
+		t.Fatalf("Failed to setup policies: %v", err)
+	}
+
+	results, err := adapter.RemovePoliciesWithResultsCtx(ctx, "p", "p", rules)
+	if err != nil {
+		t.Fatalf("RemovePoliciesWithResultsCtx() unexpected error: %v", err)
+	}
+
+	for i, result := range results {
+		expected := pgxadapter.RuleRemoved
+		if i >= 4000 {
+			expected = pgxadapter.RuleNotFound
+		}
+		if result.Outcome != expected {
+			t.Fatalf("RemovePoliciesWithResultsCtx() result %d outcome = %v, want %v", i, result.Outcome, expected)
+		}
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName).Scan(&count); err != nil {
+		t.Fatalf("Failed to count policies: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("RemovePoliciesWithResultsCtx() left %d rules, want 0", count)
+	}
+}
+
+func TestAddPoliciesChunked(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []pgxadapter.Option
+		ruleCount int
+	}{
+		{
+			name:      "default_batch_size",
+			ruleCount: 50000,
+		},
+		{
+			name:      "small_batch_size",
+			opts:      []pgxadapter.Option{pgxadapter.WithBatchSize(7)},
+			ruleCount: 100,
+		},
+		{
+			name:      "strict_small_batch_size",
+			opts:      []pgxadapter.Option{pgxadapter.WithBatchSize(7), pgxadapter.WithStrict()},
+			ruleCount: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_add_chunked_" + tt.name
+			adapter, db := setupTestAdapter(t, tableName, tt.opts...)
+
+			rules := make([][]string, tt.ruleCount)
+			for i := range rules {
+				rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+			}
+
+			// A rule rejected by the database in the last chunk must roll back every earlier chunk
+			if _, err := db.ExecContext(ctx, "ALTER TABLE "+tableName+" ADD CONSTRAINT no_poison CHECK (v0 <> 'poison')"); err != nil {
+				t.Fatalf("Failed to add constraint: %v", err)
+			}
+			poisoned := append(slices.Clone(rules), []string{"poison", "data1", "read"})
+			if err := adapter.AddPoliciesCtx(ctx, "p", "p", poisoned); err == nil {
+				t.Fatal("AddPoliciesCtx() expected error for rule violating the constraint")
+			}
+
+			var count int
+			if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName).Scan(&count); err != nil {
+				t.Fatalf("Failed to count policies: %v", err)
+			}
+			if count != 0 {
+				t.Fatalf("AddPoliciesCtx() should have rolled back, but %d rules were stored", count)
+			}
+
+			results, err := adapter.AddPoliciesWithResultsCtx(ctx, "p", "p", rules)
+			if err != nil {
+				t.Fatalf("AddPoliciesWithResultsCtx() unexpected error: %v", err)
+			}
+			for i, result := range results {
+				if result.Outcome != pgxadapter.RuleInserted {
+					t.Fatalf("AddPoliciesWithResultsCtx() result %d outcome = %v, want %v", i, result.Outcome, pgxadapter.RuleInserted)
+				}
+			}
+
+			if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName).Scan(&count); err != nil {
+				t.Fatalf("Failed to count policies: %v", err)
+			}
+			if count != tt.ruleCount {
+				t.Errorf("AddPoliciesWithResultsCtx() stored %d rules, want %d", count, tt.ruleCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/casbin/casbin/v3/persist"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// The database_sql sub-benchmarks run the same statements through GetDB, the database/sql bridge
+// the adapter used before executing on pgx directly, as the baseline to compare against.
+
+func BenchmarkLoadPolicy(b *testing.B) {
+	ctx := context.Background()
+	tableName := "casbin_bench_load_policy"
+	adapter := setupBenchAdapter(b, tableName)
+
+	rules := make([][]string, 1000)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), fmt.Sprintf("data%d", i%10), "read"}
+	}
+	if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+		b.Fatalf("Failed to setup policies: %v", err)
+	}
+
+	b.Run("pgx", func(b *testing.B) {
+		for b.Loop() {
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+				b.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+			}
+		}
+	})
+
+	b.Run("database_sql", func(b *testing.B) {
+		db := adapter.GetDB()
+		query := "SELECT ptype, v0, v1, v2, v3, v4, v5 FROM " + pgx.Identifier{tableName}.Sanitize() + " ORDER BY id"
+
+		for b.Loop() {
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := loadPolicySQL(ctx, db, query, m); err != nil {
+				b.Fatalf("loadPolicySQL() unexpected error: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkAddPolicies(b *testing.B) {
+	ctx := context.Background()
+	tableName := "casbin_bench_add_policies"
+	adapter := setupBenchAdapter(b, tableName)
+	quotedTableName := pgx.Identifier{tableName}.Sanitize()
+
+	rules := make([][]string, 100)
+	for i := range rules {
+		rules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+	}
+
+	b.Run("pgx", func(b *testing.B) {
+		for b.Loop() {
+			b.StopTimer()
+			truncateBenchTable(b, adapter, quotedTableName)
+			b.StartTimer()
+
+			if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+				b.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+			}
+		}
+	})
+
+	b.Run("database_sql", func(b *testing.B) {
+		db := adapter.GetDB()
+
+		placeholders := make([]string, len(rules))
+		args := make([]any, 0, len(rules)*7)
+		for i, rule := range rules {
+			placeholders[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, NULL, NULL, NULL)", i*4+1, i*4+2, i*4+3, i*4+4)
+			args = append(args, "p", rule[0], rule[1], rule[2])
+		}
+		query := "INSERT INTO " + quotedTableName + " (ptype, v0, v1, v2, v3, v4, v5) VALUES " +
+			strings.Join(placeholders, ", ") + " ON CONFLICT DO NOTHING RETURNING v0, v1, v2, v3, v4, v5"
+
+		for b.Loop() {
+			b.StopTimer()
+			truncateBenchTable(b, adapter, quotedTableName)
+			b.StartTimer()
+
+			if err := addPoliciesSQL(ctx, db, query, args); err != nil {
+				b.Fatalf("addPoliciesSQL() unexpected error: %v", err)
+			}
+		}
+	})
+}
+
+// loadPolicySQL loads policies through database/sql the way the adapter's LoadPolicy does
+func loadPolicySQL(ctx context.Context, db *sql.DB, query string, m model.Model) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var ptype string
+		var v0, v1, v2, v3, v4, v5 sql.NullString
+		if err := rows.Scan(&ptype, &v0, &v1, &v2, &v3, &v4, &v5); err != nil {
+			return err
+		}
+
+		line := []string{ptype}
+		for _, v := range []sql.NullString{v0, v1, v2, v3, v4, v5} {
+			if v.Valid {
+				line = append(line, v.String)
+			}
+		}
+		if err := persist.LoadPolicyArray(line, m); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// addPoliciesSQL inserts policies through database/sql the way the adapter's AddPolicies does
+func addPoliciesSQL(ctx context.Context, db *sql.DB, query string, args []any) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var v0, v1, v2, v3, v4, v5 sql.NullString
+		if err := rows.Scan(&v0, &v1, &v2, &v3, &v4, &v5); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func truncateBenchTable(b *testing.B, adapter *pgxadapter.PgxAdapter, quotedTableName string) {
+	b.Helper()
+
+	if _, err := adapter.GetPool().Exec(context.Background(), "TRUNCATE TABLE "+quotedTableName); err != nil {
+		b.Fatalf("Failed to truncate table: %v", err)
+	}
+}
+
+// setupBenchAdapter creates a pool-based adapter on a fresh table, skipping when no database is available
+func setupBenchAdapter(b *testing.B, tableName string) *pgxadapter.PgxAdapter {
+	b.Helper()
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, getTestDBURL())
+	if err != nil {
+		b.Skipf("Could not create pool for test database: %v", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		b.Skipf("Could not ping test database: %v", err)
+	}
+
+	quotedTableName := pgx.Identifier{tableName}.Sanitize()
+	_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+
+	adapter, err := pgxadapter.NewAdapterWithPool(pool, pgxadapter.WithTableName(tableName))
+	if err != nil {
+		pool.Close()
+		b.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	b.Cleanup(func() {
+		_ = adapter.Close(ctx)
+		_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+		pool.Close()
+	})
+
+	return adapter
+}
@@ -0,0 +1,60 @@
+package pgxadapter
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// WithCaseInsensitive makes rule values match regardless of case.
+// The unique index compares lower-cased values, so rules differing only in case are duplicates,
+// and removals, updates and filters match values against the same expression so they can use
+// the index. Values are stored as given and ptypes are still matched exactly.
+// On an existing table the case-insensitive unique index is added alongside the original one,
+// and creating the adapter fails if stored rules differ only in case.
+func WithCaseInsensitive() Option {
+	return func(a *PgxAdapter) {
+		a.caseInsensitive = true
+	}
+}
+
+// uniqueIndex returns the name and expressions of the index rules in table are deduplicated by
+func (a *PgxAdapter) uniqueIndex(table string) (string, string) {
+	name, format := "idx_"+table, "COALESCE(%s,'')"
+	if a.arrayStorage {
+		// The array column is NOT NULL, so it is compared as is
+		format = "%s"
+	} else if a.caseInsensitive {
+		name, format = name+"_ci", ciFormat
+	}
+
+	expressions := []string{a.columns[0]}
+	for _, col := range a.columns[1:] {
+		expressions = append(expressions, fmt.Sprintf(format, col))
+	}
+
+	return name, strings.Join(expressions, ", ")
+}
+
+// ciFormat formats the expression the case-insensitive unique index holds for a column.
+// Values are matched against the same expression so removals, updates and filters can use the index.
+const ciFormat = "lower(COALESCE(%s,''))"
+
+// valueEq returns the condition matching column against a rule value
+func (a *PgxAdapter) valueEq(column string, value string) sq.Sqlizer {
+	if a.caseInsensitive {
+		// The IS NOT NULL test keeps an empty value from matching NULL, as without WithCaseInsensitive
+		return sq.Expr(column+" IS NOT NULL AND "+fmt.Sprintf(ciFormat, column)+" = lower(?)", value)
+	}
+	return sq.Eq{column: value}
+}
+
+// valueIn returns the condition matching column against an array of rule values bound to its placeholder
+func (a *PgxAdapter) valueIn(column string) string {
+	if a.caseInsensitive {
+		// ARRAY(...) lowers the values once, leaving an array the index can be searched with
+		return column + " IS NOT NULL AND " + fmt.Sprintf(ciFormat, column) + " = ANY(ARRAY(SELECT lower(unnest(?::text[]))))"
+	}
+	return column + " = ANY(?)"
+}
@@ -0,0 +1,159 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestWithCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error
+		want [][]string
+	}{
+		{
+			name: "duplicate_ignored",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"ALICE@corp.com", "Data1", "read"}, {"bob@corp.com", "data1", "read"}})
+			},
+			want: [][]string{{"Alice@corp.com", "data1", "read"}, {"bob@corp.com", "data1", "read"}},
+		},
+		{
+			name: "remove",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemovePolicyCtx(ctx, "p", "p", []string{"alice@corp.com", "DATA1", "Read"})
+			},
+		},
+		{
+			name: "remove_filtered",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "alice@CORP.com")
+			},
+		},
+		{
+			name: "update",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"alice@corp.com", "data1", "READ"}, []string{"alice@corp.com", "data1", "write"})
+			},
+			want: [][]string{{"alice@corp.com", "data1", "write"}},
+		},
+		{
+			name: "update_filtered",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"carol@corp.com", "data1", "read"}}, 0, "ALICE@corp.com")
+				return err
+			},
+			want: [][]string{{"carol@corp.com", "data1", "read"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			adapter, _ := setupTestAdapter(t, "casbin_test_case_"+tt.name, pgxadapter.WithCaseInsensitive())
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"Alice@corp.com", "data1", "read"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			if err := tt.run(ctx, adapter); err != nil {
+				t.Fatalf("run() unexpected error: %v", err)
+			}
+
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+				t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+			}
+			got := m["p"]["p"].Policy
+			slices.SortFunc(got, slices.Compare)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("stored rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_case_filter", pgxadapter.WithCaseInsensitive())
+
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"Alice@corp.com", "data1", "read"}); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{Ptype: []string{"p"}, V0: []string{"ALICE@CORP.COM", "bob@corp.com"}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 {
+			t.Errorf("loaded %v, want the rule stored as Alice@corp.com", got)
+		}
+	})
+
+	t.Run("matches_index_expression", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var logs logBuffer
+		logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		adapter, _ := setupTestAdapter(t, "casbin_test_case_index", pgxadapter.WithCaseInsensitive(), pgxadapter.WithLogger(logger))
+
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"Alice@corp.com", "data1", "read"}); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+		logs.records(t)
+
+		if err := adapter.RemovePolicyCtx(ctx, "p", "p", []string{"ALICE@CORP.COM", "data1", "read"}); err != nil {
+			t.Fatalf("RemovePolicyCtx() unexpected error: %v", err)
+		}
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{V0: []string{"ALICE@CORP.COM", "bob@corp.com"}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+
+		// The unique index holds lower(COALESCE(col,'')), so only conditions on that expression can use it
+		var statements int
+		for _, record := range logs.records(t) {
+			sql, _ := record["sql"].(string)
+			if !strings.Contains(sql, "WHERE") {
+				continue
+			}
+			statements++
+			if !strings.Contains(sql, "lower(COALESCE(v0,''))") || strings.Contains(sql, "lower(v0)") {
+				t.Errorf("statement %q does not match v0 against the index expression", sql)
+			}
+		}
+		if statements < 2 {
+			t.Errorf("logged %d matching statements, want the removal and the filtered load", statements)
+		}
+	})
+
+	t.Run("case_sensitive_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_case_default")
+
+		rules := [][]string{{"Alice@corp.com", "data1", "read"}, {"alice@corp.com", "data1", "read"}}
+		if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+			t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+			t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 2 {
+			t.Errorf("stored %v, want both spellings", got)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package pgxadapter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ColumnMapping names the columns of the policy table, for tables laid out by another adapter.
+// Empty names keep the defaults: id, ptype, v0 to v5 and v.
+type ColumnMapping struct {
+	ID    string
+	Ptype string
+	// Values names the columns holding fields 0 to 5 of a rule.
+	Values [6]string
+	// Array names the text[] column holding a rule's fields with WithArrayStorage.
+	Array string
+}
+
+// WithColumnMapping reads and writes rules through the named columns.
+// Options and results that refer to columns, such as WithIndex, WithColumnLength and Stats,
+// keep using the names ptype and v0 to v5.
+func WithColumnMapping(mapping ColumnMapping) Option {
+	return func(a *PgxAdapter) {
+		a.columnMapping = mapping
+	}
+}
+
+// WithoutCreateTable uses the table as it is, without creating it or its indexes.
+// Without the adapter's unique index, adding a rule that is already stored adds it again.
+func WithoutCreateTable() Option {
+	return func(a *PgxAdapter) {
+		a.skipCreateTable = true
+	}
+}
+
+// resolveColumns fills in the default column names and quotes them for use in statements
+func (a *PgxAdapter) resolveColumns() {
+	m := &a.columnMapping
+	if m.ID == "" {
+		m.ID = "id"
+	}
+	if m.Ptype == "" {
+		m.Ptype = selectColumns[0]
+	}
+	for i := range m.Values {
+		if m.Values[i] == "" {
+			m.Values[i] = selectColumns[i+1]
+		}
+	}
+	if m.Array == "" {
+		m.Array = defaultArrayColumn
+	}
+
+	a.idColumn = pgx.Identifier{m.ID}.Sanitize()
+	a.columns = nil
+	for _, name := range a.columnNames() {
+		a.columns = append(a.columns, pgx.Identifier{name}.Sanitize())
+	}
+}
+
+// columnNames returns the unquoted names of the ptype and value columns, in selectColumns order,
+// or of the ptype and array columns with WithArrayStorage
+func (a *PgxAdapter) columnNames() []string {
+	if a.arrayStorage {
+		return []string{a.columnMapping.Ptype, a.columnMapping.Array}
+	}
+	return append([]string{a.columnMapping.Ptype}, a.columnMapping.Values[:]...)
+}
+
+// column returns the quoted name of the column called name in options, one of id, ptype and v0 to v5
+func (a *PgxAdapter) column(name string) (string, error) {
+	if name == "id" {
+		return a.idColumn, nil
+	}
+	i := slices.Index(selectColumns, name)
+	if i < 0 {
+		return "", fmt.Errorf("unknown column %q", name)
+	}
+	if i == 0 {
+		return a.columns[0], nil
+	}
+	return a.fieldColumn(i - 1), nil
+}
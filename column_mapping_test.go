@@ -0,0 +1,156 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// legacyMapping names the columns of a table laid out by another adapter
+var legacyMapping = pgxadapter.ColumnMapping{
+	ID:     "rule_id",
+	Ptype:  "p_type",
+	Values: [6]string{"sub", "obj", "act", "Attr 3", "attr4", "attr5"},
+}
+
+func TestWithColumnMapping(t *testing.T) {
+	t.Run("existing_table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_mapping_existing"
+
+		// The table is created outside the adapter, as another adapter would have left it
+		bootstrap, _ := setupTestAdapter(t, "casbin_test_mapping_bootstrap")
+		pool := bootstrap.GetPool()
+		quotedTableName := pgx.Identifier{tableName}.Sanitize()
+		_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName)
+		_, err := pool.Exec(ctx, `CREATE TABLE `+quotedTableName+` (
+			rule_id BIGSERIAL PRIMARY KEY,
+			p_type TEXT NOT NULL,
+			sub TEXT, obj TEXT, act VARCHAR(10), "Attr 3" TEXT, attr4 TEXT, attr5 TEXT
+		)`)
+		if err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+		t.Cleanup(func() {
+			_, _ = pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+quotedTableName)
+		})
+
+		adapter, err := pgxadapter.NewAdapterWithPool(pool,
+			pgxadapter.WithTableName(tableName),
+			pgxadapter.WithColumnMapping(legacyMapping),
+			pgxadapter.WithoutCreateTable())
+		if err != nil {
+			t.Fatalf("NewAdapterWithPool() unexpected error: %v", err)
+		}
+
+		if err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{
+			{"alice", "data1", "read"},
+			{"bob", "data2", "write", "tenant1"},
+		}); err != nil {
+			t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+		}
+		if err := adapter.AddPolicyCtx(ctx, "g", "g", []string{"alice", "admin"}); err != nil {
+			t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write"}); err != nil {
+			t.Fatalf("UpdatePolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 3, "tenant1"); err != nil {
+			t.Fatalf("RemoveFilteredPolicyCtx() unexpected error: %v", err)
+		}
+
+		var sub, act string
+		if err := pool.QueryRow(ctx, "SELECT sub, act FROM "+quotedTableName+" WHERE p_type = 'p'").Scan(&sub, &act); err != nil {
+			t.Fatalf("Failed to read mapped columns: %v", err)
+		}
+		if sub != "alice" || act != "write" {
+			t.Errorf("stored sub = %q, act = %q, want alice and write", sub, act)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{V0: []string{"alice"}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 || !slices.Equal(got[0], []string{"alice", "data1", "write"}) {
+			t.Errorf("loaded p rules = %v, want [[alice data1 write]]", got)
+		}
+		if got := m["g"]["g"].Policy; len(got) != 1 {
+			t.Errorf("loaded g rules = %v, want [[alice admin]]", got)
+		}
+
+		page, err := adapter.ListPolicies(ctx, pgxadapter.Filter{Ptype: []string{"p"}}, pgxadapter.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListPolicies() unexpected error: %v", err)
+		}
+		if page.Total != 1 {
+			t.Errorf("ListPolicies() total = %d, want 1", page.Total)
+		}
+
+		stats, err := adapter.Stats(ctx, pgxadapter.Filter{}, "v0")
+		if err != nil {
+			t.Fatalf("Stats() unexpected error: %v", err)
+		}
+		if stats.Total != 2 || len(stats.ByValue) == 0 || stats.ByValue[0].Column != "v0" {
+			t.Errorf("Stats() = %+v, want 2 rules grouped by v0", stats)
+		}
+
+		err = adapter.AddPolicyCtx(ctx, "p", "p", []string{"carol", "data3", "read_write_all"})
+		var lengthErr *pgxadapter.LengthError
+		if !errors.As(err, &lengthErr) || lengthErr.Column != "act" || lengthErr.Max != 10 {
+			t.Errorf("AddPolicyCtx() error = %v, want a LengthError on column act", err)
+		}
+	})
+
+	t.Run("created_table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_mapping_created"
+		adapter, db := setupTestAdapter(t, tableName, pgxadapter.WithColumnMapping(legacyMapping), pgxadapter.WithIndex("ptype", "v3"))
+
+		var columns []string
+		rows, err := db.Query(`SELECT column_name FROM information_schema.columns WHERE table_name = $1 ORDER BY ordinal_position`, tableName)
+		if err != nil {
+			t.Fatalf("Failed to read columns: %v", err)
+		}
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				t.Fatalf("Failed to scan column: %v", err)
+			}
+			columns = append(columns, column)
+		}
+		rows.Close()
+
+		want := []string{"rule_id", "p_type", "sub", "obj", "act", "Attr 3", "attr4", "attr5"}
+		if !slices.Equal(columns, want) {
+			t.Errorf("created columns = %v, want %v", columns, want)
+		}
+
+		rule := []string{"alice", "data1", "read"}
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("AddPolicyCtx() duplicate unexpected error: %v", err)
+		}
+		if err := adapter.RemovePolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("RemovePolicyCtx() unexpected error: %v", err)
+		}
+
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + tableName).Scan(&count); err != nil {
+			t.Fatalf("Failed to count rules: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("stored %d rules, want 0", count)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// defaultColumnLength is the VARCHAR length of columns not configured with WithColumnLength or WithTextColumns
+const defaultColumnLength = 100
+
+// WithColumnLength declares the given columns, any of ptype and v0 to v5, as VARCHAR(length)
+// when the table is created. Without columns it applies to all of them. The default is VARCHAR(100).
+// Unknown columns and lengths below 1 are ignored.
+func WithColumnLength(length int, columns ...string) Option {
+	return func(a *PgxAdapter) {
+		if length > 0 {
+			a.setColumnLength(length, columns)
+		}
+	}
+}
+
+// WithTextColumns declares the given columns, any of ptype and v0 to v5, as TEXT when the table
+// is created. Without columns it applies to all of them. Unknown columns are ignored.
+func WithTextColumns(columns ...string) Option {
+	return func(a *PgxAdapter) {
+		a.setColumnLength(0, columns)
+	}
+}
+
+// setColumnLength records length, with 0 meaning TEXT, for columns or all columns if none are given
+func (a *PgxAdapter) setColumnLength(length int, columns []string) {
+	if a.columnLengths == nil {
+		a.columnLengths = make(map[string]int)
+	}
+	if len(columns) == 0 {
+		columns = selectColumns
+	}
+	for _, column := range columns {
+		if slices.Contains(selectColumns, column) {
+			a.columnLengths[column] = length
+		}
+	}
+}
+
+// columnType returns the SQL type column is created with
+func (a *PgxAdapter) columnType(column string) string {
+	length, ok := a.columnLengths[column]
+	if !ok {
+		length = defaultColumnLength
+	}
+	if length == 0 {
+		return "TEXT"
+	}
+	return fmt.Sprintf("VARCHAR(%d)", length)
+}
+
+// loadColumnLengths reads the lengths the tables' columns actually allow, so rules are checked
+// against existing tables even when they were created with other types. When rules are stored in
+// several tables, the shortest length of each column applies.
+func (a *PgxAdapter) loadColumnLengths(ctx context.Context) error {
+	rows, err := a.db.Query(ctx, `SELECT attname,
+		CASE WHEN atttypid IN ('varchar'::regtype, 'bpchar'::regtype) AND atttypmod >= 4 THEN atttypmod - 4 ELSE 0 END
+		FROM pg_attribute
+		WHERE attrelid = ANY($1::regclass[]) AND attnum > 0 AND NOT attisdropped`,
+		a.quotedTables())
+	if err != nil {
+		return fmt.Errorf("failed to read column lengths: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column string
+		var length int
+		if err := rows.Scan(&column, &length); err != nil {
+			return fmt.Errorf("failed to scan column length: %w", err)
+		}
+		i := slices.Index(a.columnNames(), column)
+		if i < 0 || length == 0 {
+			continue
+		}
+		if a.columnLimits[i] == 0 || length < a.columnLimits[i] {
+			a.columnLimits[i] = length
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read column lengths: %w", err)
+	}
+
+	return nil
+}
+
+// LengthError reports a value longer than its column allows.
+// It matches ErrValueTooLong with errors.Is.
+type LengthError struct {
+	// Column is the name of the table column the value is stored in.
+	Column string
+	// Field is the value's index within the rule, or -1 for the ptype.
+	Field  int
+	Length int
+	Max    int
+}
+
+func (e *LengthError) Error() string {
+	if e.Field < 0 {
+		return fmt.Sprintf("%v: ptype has %d characters, column %s allows %d", ErrValueTooLong, e.Length, e.Column, e.Max)
+	}
+	return fmt.Sprintf("%v: field %d has %d characters, column %s allows %d", ErrValueTooLong, e.Field, e.Length, e.Column, e.Max)
+}
+
+func (e *LengthError) Unwrap() error {
+	return ErrValueTooLong
+}
+
+// checkValueLengths returns a LengthError if ptype or a field of rule is longer than its column allows
+func (a *PgxAdapter) checkValueLengths(ptype string, rule []string) error {
+	values := append([]string{ptype}, rule...)
+	for i, value := range values[:min(len(values), len(a.columnLimits))] {
+		limit := a.columnLimits[i]
+		if limit == 0 {
+			continue
+		}
+		if n := utf8.RuneCountInString(value); n > limit {
+			return &LengthError{Column: a.columnNames()[i], Field: i - 1, Length: n, Max: limit}
+		}
+	}
+	return nil
+}
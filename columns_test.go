@@ -0,0 +1,118 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestColumnTypes(t *testing.T) {
+	arn := "arn:aws:s3:::" + strings.Repeat("bucket/", 30)
+
+	tests := []struct {
+		name  string
+		opts  []pgxadapter.Option
+		want  map[string]string
+		rule  []string
+		field int
+	}{
+		{
+			name:  "default",
+			want:  map[string]string{"ptype": "character varying(100)", "v1": "character varying(100)"},
+			rule:  []string{"alice", arn, "read"},
+			field: 1,
+		},
+		{
+			name: "text_columns",
+			opts: []pgxadapter.Option{pgxadapter.WithTextColumns("v1")},
+			want: map[string]string{"v0": "character varying(100)", "v1": "text"},
+			rule: []string{"alice", arn, "read"},
+		},
+		{
+			name:  "custom_length",
+			opts:  []pgxadapter.Option{pgxadapter.WithColumnLength(1000), pgxadapter.WithColumnLength(8, "v2")},
+			want:  map[string]string{"ptype": "character varying(1000)", "v1": "character varying(1000)", "v2": "character varying(8)"},
+			rule:  []string{"alice", arn, "read_write"},
+			field: 2,
+		},
+		{
+			name: "unicode_length_in_characters",
+			opts: []pgxadapter.Option{pgxadapter.WithColumnLength(4, "v2")},
+			want: map[string]string{"v2": "character varying(4)"},
+			rule: []string{"alice", "data1", "lésé"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_columns_" + tt.name
+			adapter, db := setupTestAdapter(t, tableName, tt.opts...)
+
+			for column, want := range tt.want {
+				var got string
+				err := db.QueryRow(`SELECT format_type(atttypid, atttypmod) FROM pg_attribute
+					WHERE attrelid = $1::regclass AND attname = $2`, tableName, column).Scan(&got)
+				if err != nil {
+					t.Fatalf("Failed to read column type: %v", err)
+				}
+				if got != want {
+					t.Errorf("column %s type = %q, want %q", column, got, want)
+				}
+			}
+
+			err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"bob", "data2", "write"}, tt.rule})
+			if tt.field == 0 {
+				if err != nil {
+					t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, pgxadapter.ErrValueTooLong) {
+				t.Fatalf("AddPoliciesCtx() error = %v, want %v", err, pgxadapter.ErrValueTooLong)
+			}
+			var policyErr *pgxadapter.PolicyError
+			if !errors.As(err, &policyErr) || policyErr.Index != 1 {
+				t.Errorf("AddPoliciesCtx() error = %v, want a PolicyError at index 1", err)
+			}
+			var lengthErr *pgxadapter.LengthError
+			if !errors.As(err, &lengthErr) {
+				t.Fatalf("AddPoliciesCtx() error = %T, want a LengthError", err)
+			}
+			if lengthErr.Field != tt.field || lengthErr.Column != "v"+string(rune('0'+tt.field)) {
+				t.Errorf("LengthError field = %d, column = %s, want field %d", lengthErr.Field, lengthErr.Column, tt.field)
+			}
+		})
+	}
+
+	t.Run("existing_table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_columns_existing"
+		existing, _ := setupTestAdapter(t, tableName, pgxadapter.WithColumnLength(10))
+
+		// Reopening with other options leaves the table as it is, and checks follow the table
+		adapter, err := pgxadapter.NewAdapterWithPool(existing.GetPool(), pgxadapter.WithTableName(tableName), pgxadapter.WithTextColumns())
+		if err != nil {
+			t.Fatalf("NewAdapterWithPool() unexpected error: %v", err)
+		}
+
+		err = adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read_and_write"})
+		var lengthErr *pgxadapter.LengthError
+		if !errors.As(err, &lengthErr) || lengthErr.Max != 10 {
+			t.Errorf("AddPolicyCtx() error = %v, want a LengthError with Max 10", err)
+		}
+
+		err = adapter.AddPolicyCtx(ctx, "a_very_long_ptype", "a_very_long_ptype", []string{"alice"})
+		if !errors.As(err, &lengthErr) || lengthErr.Field != -1 || lengthErr.Column != "ptype" {
+			t.Errorf("AddPolicyCtx() error = %v, want a LengthError on ptype", err)
+		}
+	})
+}
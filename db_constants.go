@@ -1,15 +1,11 @@
 package pgxadapter
 
-var (
-	insertColumns = []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
-	selectColumns = []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
+// valueColumns is the number of columns holding rule fields
+const valueColumns = 6
 
-	colParams = map[int]string{
-		0: "v0",
-		1: "v1",
-		2: "v2",
-		3: "v3",
-		4: "v4",
-		5: "v5",
-	}
-)
+// defaultArrayColumn names the text[] column used by WithArrayStorage
+const defaultArrayColumn = "v"
+
+// selectColumns names the ptype and value columns as options and results refer to them.
+// WithColumnMapping maps them to the table's own columns.
+var selectColumns = []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
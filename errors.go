@@ -0,0 +1,97 @@
+package pgxadapter
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPolicyExists is returned in strict mode when adding a rule that is already stored.
+	ErrPolicyExists = errors.New("policy already exists")
+	// ErrPolicyNotFound is returned when a rule to update, or in strict mode to remove, is not stored.
+	ErrPolicyNotFound = errors.New("policy not found")
+	// ErrInvalidFilter is returned when a filter has an unsupported type or refers to an unknown column.
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrFieldIndexOutOfRange is returned when a field index does not refer to one of v0 to v5.
+	ErrFieldIndexOutOfRange = errors.New("field index out of range")
+	// ErrRuleTooLong is returned when a rule has more fields than the table has value columns.
+	ErrRuleTooLong = errors.New("rule has too many fields")
+	// ErrValueTooLong is returned, wrapped in a LengthError, when a value is longer than its column allows.
+	ErrValueTooLong = errors.New("value too long for column")
+	// ErrUnknownPtype is returned when a rule's ptype is not defined by the model given with WithModel.
+	ErrUnknownPtype = errors.New("unknown ptype")
+	// ErrRuleArity is returned when a rule's field count does not match the model given with WithModel.
+	ErrRuleArity = errors.New("rule does not match the model's fields")
+	// ErrInvalidValue is returned when a field value fails a validator given with WithFieldRule.
+	ErrInvalidValue = errors.New("invalid field value")
+	// ErrFilteredSave is returned when saving after a filtered load, which would discard unloaded rules.
+	ErrFilteredSave = errors.New("cannot save a filtered policy")
+	// ErrNotListPartitioned is returned by AttachPartition and DetachPartition unless the table is partitioned with PartitionList.
+	ErrNotListPartitioned = errors.New("table is not list partitioned")
+	// ErrClosed is returned by every operation started after Close.
+	ErrClosed = errors.New("adapter is closed")
+)
+
+// PolicyError records a failed operation on a single rule.
+// Err is the sentinel or database error describing the failure and is available through errors.Is and errors.As.
+type PolicyError struct {
+	Ptype string
+	Rule  []string
+	// Index is the rule's position within a batch, or -1 for single-rule operations.
+	Index int
+	Err   error
+}
+
+func (e *PolicyError) Error() string {
+	if e.Index >= 0 {
+		return fmt.Sprintf("%v: %s %v at index %d", e.Err, e.Ptype, e.Rule, e.Index)
+	}
+	return fmt.Sprintf("%v: %s %v", e.Err, e.Ptype, e.Rule)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// FilterError records a failed operation on the rules matching a field filter.
+// Err is the sentinel or database error describing the failure and is available through errors.Is and errors.As.
+type FilterError struct {
+	Ptype       string
+	FieldIndex  int
+	FieldValues []string
+	Err         error
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%v: %s field index %d values %v", e.Err, e.Ptype, e.FieldIndex, e.FieldValues)
+}
+
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// newPolicyError wraps err with the rule a single-rule operation failed on.
+func newPolicyError(err error, ptype string, rule []string) error {
+	return &PolicyError{Ptype: ptype, Rule: rule, Index: -1, Err: err}
+}
+
+// newBatchPolicyError wraps err with the rule at index that a batch operation failed on.
+func newBatchPolicyError(err error, ptype string, rule []string, index int) error {
+	return &PolicyError{Ptype: ptype, Rule: rule, Index: index, Err: err}
+}
+
+// checkFieldIndex returns ErrFieldIndexOutOfRange unless fieldIndex refers to a stored field.
+func (a *PgxAdapter) checkFieldIndex(ptype string, fieldIndex int, fieldValues []string) error {
+	if fieldIndex < 0 || fieldIndex >= a.maxFields() {
+		return &FilterError{Ptype: ptype, FieldIndex: fieldIndex, FieldValues: fieldValues, Err: ErrFieldIndexOutOfRange}
+	}
+	return nil
+}
+
+// checkRuleLength returns ErrRuleTooLong if rule has more fields than can be stored.
+func (a *PgxAdapter) checkRuleLength(rule []string) error {
+	if len(rule) > a.maxFields() {
+		return ErrRuleTooLong
+	}
+	return nil
+}
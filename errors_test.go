@@ -0,0 +1,71 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_errors"
+	adapter, _ := setupTestAdapter(t, tableName)
+
+	m, _ := model.NewModelFromString(TestModelText)
+
+	err := adapter.LoadFilteredPolicyCtx(ctx, m, "invalid filter")
+	if !errors.Is(err, pgxadapter.ErrInvalidFilter) {
+		t.Errorf("LoadFilteredPolicyCtx() error = %v, want ErrInvalidFilter", err)
+	}
+
+	err = adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 6, "alice")
+	var filterErr *pgxadapter.FilterError
+	if !errors.Is(err, pgxadapter.ErrFieldIndexOutOfRange) || !errors.As(err, &filterErr) {
+		t.Errorf("RemoveFilteredPolicyCtx() error = %v, want FilterError wrapping ErrFieldIndexOutOfRange", err)
+	} else if filterErr.FieldIndex != 6 || filterErr.Ptype != "p" {
+		t.Errorf("RemoveFilteredPolicyCtx() FilterError = %+v, want ptype p field index 6", filterErr)
+	}
+
+	tooLong := []string{"a", "b", "c", "d", "e", "f", "g"}
+	err = adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, tooLong})
+	var policyErr *pgxadapter.PolicyError
+	if !errors.Is(err, pgxadapter.ErrRuleTooLong) || !errors.As(err, &policyErr) {
+		t.Errorf("AddPoliciesCtx() error = %v, want PolicyError wrapping ErrRuleTooLong", err)
+	} else if policyErr.Index != 1 || !slices.Equal(policyErr.Rule, tooLong) {
+		t.Errorf("AddPoliciesCtx() PolicyError = %+v, want index 1 rule %v", policyErr, tooLong)
+	}
+
+	oldRule := []string{"bob", "data2", "write"}
+	err = adapter.UpdatePolicyCtx(ctx, "p", "p", oldRule, []string{"bob", "data2", "read"})
+	if !errors.Is(err, pgxadapter.ErrPolicyNotFound) || !errors.As(err, &policyErr) {
+		t.Errorf("UpdatePolicyCtx() error = %v, want PolicyError wrapping ErrPolicyNotFound", err)
+	} else if policyErr.Index != -1 || policyErr.Ptype != "p" || !slices.Equal(policyErr.Rule, oldRule) {
+		t.Errorf("UpdatePolicyCtx() PolicyError = %+v, want ptype p rule %v", policyErr, oldRule)
+	}
+
+	if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{V0: []string{"alice"}}); err != nil {
+		t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+	}
+	err = adapter.SavePolicyCtx(ctx, m)
+	if !errors.Is(err, pgxadapter.ErrFilteredSave) {
+		t.Errorf("SavePolicyCtx() after filtered load error = %v, want ErrFilteredSave", err)
+	}
+
+	// Database errors keep the underlying *pgconn.PgError
+	_, _ = adapter.GetDB().ExecContext(ctx, "DROP TABLE "+pgx.Identifier{tableName}.Sanitize())
+	err = adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || !errors.As(err, &policyErr) {
+		t.Errorf("AddPolicyCtx() error = %v, want PolicyError wrapping *pgconn.PgError", err)
+	} else if pgErr.Code != "42P01" {
+		t.Errorf("AddPolicyCtx() SQLSTATE = %s, want 42P01", pgErr.Code)
+	}
+}
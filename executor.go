@@ -0,0 +1,175 @@
+package pgxadapter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgxConn is the pgx-native interface the adapter executes statements through.
+// It is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type pgxConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// txOptionsBeginner is implemented by the pgxConns that can start a transaction with options.
+// A pgx.Tx cannot: beginning on it creates a savepoint, which inherits the outer isolation level.
+type txOptionsBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// beginWithOptions starts a transaction on conn, applying opts when conn supports them
+func beginWithOptions(ctx context.Context, conn pgxConn, opts pgx.TxOptions) (pgx.Tx, error) {
+	if b, ok := conn.(txOptionsBeginner); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return conn.Begin(ctx)
+}
+
+// execBatch pipelines the statements queued in b in a single round trip and calls each with
+// the outcome of every statement in queue order. Iteration stops at the first error each returns.
+func execBatch(ctx context.Context, conn pgxConn, b *pgx.Batch, each func(i int, tag pgconn.CommandTag, err error) error) error {
+	br := conn.SendBatch(ctx, b)
+
+	for i := range b.Len() {
+		tag, err := br.Exec()
+		if err := each(i, tag, err); err != nil {
+			br.Close() //nolint:errcheck
+			return err
+		}
+	}
+
+	return br.Close()
+}
+
+// lockedConn runs statements on a single *pgx.Conn, holding mu for the lifetime of each
+// statement's result set or transaction since a pgx.Conn is not safe for concurrent use.
+type lockedConn struct {
+	mu   sync.Mutex
+	conn *pgx.Conn
+}
+
+var (
+	_ pgxConn           = (*lockedConn)(nil)
+	_ txOptionsBeginner = (*lockedConn)(nil)
+)
+
+func (c *lockedConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn.Exec(ctx, sql, args...)
+}
+
+func (c *lockedConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.mu.Lock()
+
+	rows, err := c.conn.Query(ctx, sql, args...)
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	return &lockedRows{Rows: rows, unlock: c.mu.Unlock}, nil
+}
+
+func (c *lockedConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c.mu.Lock()
+	return &lockedRow{Row: c.conn.QueryRow(ctx, sql, args...), unlock: c.mu.Unlock}
+}
+
+func (c *lockedConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	c.mu.Lock()
+	return &lockedBatchResults{BatchResults: c.conn.SendBatch(ctx, b), unlock: c.mu.Unlock}
+}
+
+func (c *lockedConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.BeginTx(ctx, pgx.TxOptions{})
+}
+
+func (c *lockedConn) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	c.mu.Lock()
+
+	tx, err := c.conn.BeginTx(ctx, txOptions)
+	if err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	return &lockedTx{Tx: tx, unlock: c.mu.Unlock}, nil
+}
+
+// lockedTx is a transaction on a lockedConn's connection, which stays locked until it ends
+type lockedTx struct {
+	pgx.Tx
+	unlock func()
+	done   bool
+}
+
+func (t *lockedTx) Commit(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	defer t.unlock()
+
+	return t.Tx.Commit(ctx)
+}
+
+func (t *lockedTx) Rollback(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	defer t.unlock()
+
+	return t.Tx.Rollback(ctx)
+}
+
+// lockedRows releases its connection's lock when closed
+type lockedRows struct {
+	pgx.Rows
+	unlock func()
+	closed bool
+}
+
+func (r *lockedRows) Close() {
+	r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.unlock()
+	}
+}
+
+// lockedBatchResults releases its connection's lock when closed
+type lockedBatchResults struct {
+	pgx.BatchResults
+	unlock func()
+	closed bool
+}
+
+func (r *lockedBatchResults) Close() error {
+	err := r.BatchResults.Close()
+	if !r.closed {
+		r.closed = true
+		r.unlock()
+	}
+	return err
+}
+
+// lockedRow releases its connection's lock once scanned
+type lockedRow struct {
+	pgx.Row
+	unlock func()
+}
+
+func (r *lockedRow) Scan(dest ...any) error {
+	defer r.unlock()
+	return r.Row.Scan(dest...)
+}
@@ -0,0 +1,211 @@
+package pgxadapter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer rewrites a field value before it is stored or matched against stored values.
+type Normalizer func(value string) string
+
+// Validator returns an error if a field value may not be stored.
+type Validator func(value string) error
+
+var (
+	// TrimSpace removes leading and trailing white space.
+	TrimSpace Normalizer = strings.TrimSpace
+	// ToLower maps a value to lower case.
+	ToLower Normalizer = strings.ToLower
+	// NFC converts a value to Unicode normalization form C.
+	NFC Normalizer = norm.NFC.String
+)
+
+// MatchRegexp returns a Validator accepting values matched by re.
+func MatchRegexp(re *regexp.Regexp) Validator {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("does not match %s", re)
+		}
+		return nil
+	}
+}
+
+// MaxLength returns a Validator accepting values of at most n characters.
+func MaxLength(n int) Validator {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("longer than %d characters", n)
+		}
+		return nil
+	}
+}
+
+// OneOf returns a Validator accepting only the given values.
+func OneOf(values ...string) Validator {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("not one of %q", values)
+		}
+		return nil
+	}
+}
+
+// FieldRule normalizes and validates one field of a ptype's rules.
+// Empty values are left alone, since they are stored as NULL and act as wildcards in removals.
+type FieldRule struct {
+	// Normalize is applied in order to every value written, and to the values of rules and
+	// filters used to remove, update or load rules, so they match what was stored.
+	Normalize []Normalizer
+	// Validate is applied to normalized values written by AddPolicy, AddPolicies, UpdatePolicy,
+	// UpdatePolicies, UpdateFilteredPolicies and SavePolicy. The first failure rejects the write
+	// with ErrInvalidValue.
+	Validate []Validator
+}
+
+// WithFieldRule normalizes and validates field index, 0 to 5, of ptype's rules.
+// Rules given for the same field are combined in the order the options are applied.
+// Indexes outside 0 to 5 are ignored.
+func WithFieldRule(ptype string, index int, rule FieldRule) Option {
+	return func(a *PgxAdapter) {
+		if index < 0 || index >= valueColumns {
+			return
+		}
+		if a.fieldRules == nil {
+			a.fieldRules = make(map[string]*[6]FieldRule)
+		}
+		fields, ok := a.fieldRules[ptype]
+		if !ok {
+			fields = &[6]FieldRule{}
+			a.fieldRules[ptype] = fields
+		}
+		fields[index].Normalize = append(fields[index].Normalize, rule.Normalize...)
+		fields[index].Validate = append(fields[index].Validate, rule.Validate...)
+	}
+}
+
+// normalize applies the field's normalizers to value
+func (r *FieldRule) normalize(value string) string {
+	if value == "" {
+		return value
+	}
+	for _, normalize := range r.Normalize {
+		value = normalize(value)
+	}
+	return value
+}
+
+// normalizeRule returns rule with ptype's normalizers applied, copying it if any apply
+func (a *PgxAdapter) normalizeRule(ptype string, rule []string) []string {
+	fields, ok := a.fieldRules[ptype]
+	if !ok {
+		return rule
+	}
+
+	normalized := slices.Clone(rule)
+	for i := range min(len(normalized), len(fields)) {
+		normalized[i] = fields[i].normalize(normalized[i])
+	}
+	return normalized
+}
+
+// normalizeRules applies normalizeRule to each of rules
+func (a *PgxAdapter) normalizeRules(ptype string, rules [][]string) [][]string {
+	if _, ok := a.fieldRules[ptype]; !ok {
+		return rules
+	}
+
+	normalized := make([][]string, len(rules))
+	for i, rule := range rules {
+		normalized[i] = a.normalizeRule(ptype, rule)
+	}
+	return normalized
+}
+
+// normalizeFieldValues applies ptype's normalizers to filter values starting at fieldIndex
+func (a *PgxAdapter) normalizeFieldValues(ptype string, fieldIndex int, fieldValues []string) []string {
+	fields, ok := a.fieldRules[ptype]
+	if !ok {
+		return fieldValues
+	}
+
+	normalized := slices.Clone(fieldValues)
+	for i := range normalized {
+		if i+fieldIndex >= len(fields) {
+			break
+		}
+		normalized[i] = fields[i+fieldIndex].normalize(normalized[i])
+	}
+	return normalized
+}
+
+// normalizeFilter applies the normalizers of the ptypes a filter can match to its values.
+// A filter spanning ptypes normalized differently matches each value in every form it may be stored in.
+func (a *PgxAdapter) normalizeFilter(filter Filter) Filter {
+	if len(a.fieldRules) == 0 {
+		return filter
+	}
+
+	// The field rules that apply, with nil standing for ptypes stored as given
+	var applicable []*[6]FieldRule
+	if len(filter.Ptype) == 0 {
+		applicable = append(applicable, nil)
+		for _, fields := range a.fieldRules {
+			applicable = append(applicable, fields)
+		}
+	} else {
+		for _, ptype := range filter.Ptype {
+			applicable = append(applicable, a.fieldRules[ptype])
+		}
+	}
+
+	normalize := func(index int, values []string) []string {
+		var normalized []string
+		for _, value := range values {
+			for _, fields := range applicable {
+				v := value
+				if fields != nil {
+					v = fields[index].normalize(value)
+				}
+				if !slices.Contains(normalized, v) {
+					normalized = append(normalized, v)
+				}
+			}
+		}
+		return normalized
+	}
+
+	filter.V0 = normalize(0, filter.V0)
+	filter.V1 = normalize(1, filter.V1)
+	filter.V2 = normalize(2, filter.V2)
+	filter.V3 = normalize(3, filter.V3)
+	filter.V4 = normalize(4, filter.V4)
+	filter.V5 = normalize(5, filter.V5)
+
+	return filter
+}
+
+// checkRuleValues returns ErrInvalidValue if a field of rule fails one of ptype's validators
+func (a *PgxAdapter) checkRuleValues(ptype string, rule []string) error {
+	fields, ok := a.fieldRules[ptype]
+	if !ok {
+		return nil
+	}
+
+	for i, value := range rule[:min(len(rule), len(fields))] {
+		if value == "" {
+			continue
+		}
+		for _, validate := range fields[i].Validate {
+			if err := validate(value); err != nil {
+				return fmt.Errorf("%w: field %d value %q: %w", ErrInvalidValue, i, value, err)
+			}
+		}
+	}
+
+	return nil
+}
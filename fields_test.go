@@ -0,0 +1,179 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestFieldRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		normalizer pgxadapter.Normalizer
+		value      string
+		want       string
+	}{
+		{name: "trim_space", normalizer: pgxadapter.TrimSpace, value: "  alice\t", want: "alice"},
+		{name: "to_lower", normalizer: pgxadapter.ToLower, value: "/Data/ONE", want: "/data/one"},
+		{name: "nfc", normalizer: pgxadapter.NFC, value: "cafe\u0301", want: "caf\u00e9"},
+	}
+	for _, tt := range tests {
+		if got := tt.normalizer(tt.value); got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+
+	validators := []struct {
+		name      string
+		validator pgxadapter.Validator
+		valid     string
+		invalid   string
+	}{
+		{name: "match_regexp", validator: pgxadapter.MatchRegexp(regexp.MustCompile(`^user:`)), valid: "user:1", invalid: "alice"},
+		{name: "max_length", validator: pgxadapter.MaxLength(4), valid: "café", invalid: "cafés"},
+		{name: "one_of", validator: pgxadapter.OneOf("read", "write"), valid: "read", invalid: "delete"},
+	}
+	for _, tt := range validators {
+		if err := tt.validator(tt.valid); err != nil {
+			t.Errorf("%s(%q) unexpected error: %v", tt.name, tt.valid, err)
+		}
+		if err := tt.validator(tt.invalid); err == nil {
+			t.Errorf("%s(%q) expected error", tt.name, tt.invalid)
+		}
+	}
+}
+
+func TestWithFieldRule(t *testing.T) {
+	subjectPattern := regexp.MustCompile(`^(user:[0-9a-f-]{36}|group:[a-z0-9-]+)$`)
+	opts := []pgxadapter.Option{
+		pgxadapter.WithFieldRule("p", 0, pgxadapter.FieldRule{
+			Normalize: []pgxadapter.Normalizer{pgxadapter.TrimSpace},
+			Validate:  []pgxadapter.Validator{pgxadapter.MatchRegexp(subjectPattern)},
+		}),
+		pgxadapter.WithFieldRule("p", 1, pgxadapter.FieldRule{
+			Normalize: []pgxadapter.Normalizer{pgxadapter.TrimSpace, pgxadapter.ToLower, pgxadapter.NFC},
+			Validate:  []pgxadapter.Validator{pgxadapter.MaxLength(20)},
+		}),
+		pgxadapter.WithFieldRule("p", 2, pgxadapter.FieldRule{
+			Validate: []pgxadapter.Validator{pgxadapter.OneOf("read", "write")},
+		}),
+	}
+
+	tests := []struct {
+		name    string
+		run     func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error
+		wantErr bool
+		want    [][]string
+	}{
+		{
+			name: "normalized_on_add",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{" group:admins ", "/Reports/Café", "read"})
+			},
+			want: [][]string{{"group:admins", "/reports/café", "read"}, {"group:dev", "/data", "write"}},
+		},
+		{
+			name: "invalid_subject",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"group:ops", "/data", "read"}, {"alice", "/data", "read"}})
+			},
+			wantErr: true,
+			want:    [][]string{{"group:dev", "/data", "write"}},
+		},
+		{
+			name: "invalid_action_on_update",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"group:dev", "/data", "write"}, []string{"group:dev", "/data", "delete"})
+			},
+			wantErr: true,
+			want:    [][]string{{"group:dev", "/data", "write"}},
+		},
+		{
+			name: "object_too_long",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"group:dev", "/a/very/long/object/path", "read"})
+			},
+			wantErr: true,
+			want:    [][]string{{"group:dev", "/data", "write"}},
+		},
+		{
+			name: "remove_matches_normalized",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemovePolicyCtx(ctx, "p", "p", []string{"group:dev ", "/DATA", "write"})
+			},
+			want: nil,
+		},
+		{
+			name: "remove_filtered_matches_normalized",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 1, " /Data")
+			},
+			want: nil,
+		},
+		{
+			name: "ungoverned_ptype_stored_as_given",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "g", "g", []string{"Alice ", "group:dev"})
+			},
+			want: [][]string{{"group:dev", "/data", "write"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			adapter, _ := setupTestAdapter(t, "casbin_test_field_rule_"+tt.name, opts...)
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"group:dev", "/Data", "write"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			err := tt.run(ctx, adapter)
+			if tt.wantErr {
+				if !errors.Is(err, pgxadapter.ErrInvalidValue) {
+					t.Fatalf("run() error = %v, want %v", err, pgxadapter.ErrInvalidValue)
+				}
+			} else if err != nil {
+				t.Fatalf("run() unexpected error: %v", err)
+			}
+
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{Ptype: []string{"p"}}); err != nil {
+				t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+			}
+			got := m["p"]["p"].Policy
+			slices.SortFunc(got, slices.Compare)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("stored rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("filter_matches_normalized", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_field_rule_filter", opts...)
+
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"group:dev", "/Data", "write"}); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{Ptype: []string{"p"}, V1: []string{"/DATA "}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 {
+			t.Errorf("loaded %v, want the rule stored as /data", got)
+		}
+	})
+}
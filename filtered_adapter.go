@@ -2,7 +2,6 @@ package pgxadapter
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
@@ -40,7 +39,15 @@ func (a *PgxAdapter) IsFiltered() bool {
 
 // LoadFilteredPolicyCtx loads only policy rules that match the filter.
 // Supports Filter for single filter or BatchFilter for OR-based filtering.
-func (a *PgxAdapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Model, filter any) error {
+func (a *PgxAdapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Model, filter any) (err error) {
+	ctx, op := a.startOperation(ctx, "LoadFilteredPolicy", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
 	if filter == nil {
 		a.mu.Lock()
 		a.isFiltered = false
@@ -61,100 +68,119 @@ func (a *PgxAdapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Mode
 	case []Filter:
 		filters = f
 	default:
-		return fmt.Errorf("invalid filter type")
+		return fmt.Errorf("%w: unsupported filter type %T", ErrInvalidFilter, filter)
 	}
 
 	a.mu.Lock()
 	a.isFiltered = true
 	a.mu.Unlock()
 
+	var loaded int
 	for _, filterValue := range filters {
-		if err := a.loadFilteredPolicies(ctx, model, filterValue); err != nil {
+		filterValue = a.normalizeFilter(filterValue)
+
+		var lines [][]string
+		err := a.retry(ctx, true, func() error {
+			var err error
+			lines, err = a.loadFilteredPolicies(ctx, filterValue)
+			return err
+		})
+		if err != nil {
 			return err
 		}
+
+		for _, line := range lines {
+			if err := persist.LoadPolicyArray(line, model); err != nil {
+				return err
+			}
+		}
+		loaded += len(lines)
 	}
+	op.setRuleCount(loaded)
+	op.setRowsAffected(int64(loaded))
 
 	return nil
 }
 
-func (a *PgxAdapter) loadFilteredPolicies(ctx context.Context, model model.Model, filterValue Filter) error {
-	query := a.psql.
-		Select(selectColumns...).
-		From(a.tableName).
-		OrderBy("id")
+// loadFilteredPolicies reads the rules matching filterValue as policy lines
+func (a *PgxAdapter) loadFilteredPolicies(ctx context.Context, filterValue Filter) ([][]string, error) {
+	fields, args := a.filterArgs(filterValue)
 
-	if len(filterValue.Ptype) > 0 {
-		query = query.Where(sq.Eq{"ptype": filterValue.Ptype})
-	}
-	if len(filterValue.V0) > 0 {
-		query = query.Where(sq.Eq{"v0": filterValue.V0})
-	}
-	if len(filterValue.V1) > 0 {
-		query = query.Where(sq.Eq{"v1": filterValue.V1})
-	}
-	if len(filterValue.V2) > 0 {
-		query = query.Where(sq.Eq{"v2": filterValue.V2})
-	}
-	if len(filterValue.V3) > 0 {
-		query = query.Where(sq.Eq{"v3": filterValue.V3})
-	}
-	if len(filterValue.V4) > 0 {
-		query = query.Where(sq.Eq{"v4": filterValue.V4})
-	}
-	if len(filterValue.V5) > 0 {
-		query = query.Where(sq.Eq{"v5": filterValue.V5})
-	}
+	relation := a.relation(filterValue.Ptype)
+	sqlQuery, err := a.stmts.get(statementKey{table: relation, shape: shapeLoadFiltered, fields: fields}, func() (string, error) {
+		query := a.psql.
+			Select(a.columns...).
+			From(relation).
+			OrderBy(a.idColumn)
 
-	sqlQuery, args, err := query.ToSql()
+		sqlQuery, _, err := a.applyFilter(query, filterValue).ToSql()
+		return sqlQuery, err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to build query: %w", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := a.db.QueryContext(ctx, sqlQuery, args...)
+	rows, err := a.reader(ctx).Query(ctx, sqlQuery, args...)
 	if err != nil {
-		return fmt.Errorf("failed to query policies: %w", err)
+		return nil, fmt.Errorf("failed to query policies: %w", err)
 	}
 	defer rows.Close()
 
+	var lines [][]string
+	scanner := a.newRuleScanner()
 	for rows.Next() {
-		var ptypeVal string
-		var v0, v1, v2, v3, v4, v5 sql.NullString
-
-		if err := rows.Scan(&ptypeVal, &v0, &v1, &v2, &v3, &v4, &v5); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
+		if err := rows.Scan(scanner.dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		line := []string{ptypeVal}
+		lines = append(lines, scanner.line())
+	}
 
-		if v0.Valid {
-			line = append(line, v0.String)
-		}
-		if v1.Valid {
-			line = append(line, v1.String)
-		}
-		if v2.Valid {
-			line = append(line, v2.String)
-		}
-		if v3.Valid {
-			line = append(line, v3.String)
-		}
-		if v4.Valid {
-			line = append(line, v4.String)
-		}
-		if v5.Valid {
-			line = append(line, v5.String)
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
 
-		if err := persist.LoadPolicyArray(line, model); err != nil {
-			return err
+	return lines, nil
+}
+
+// filterColumnValues returns the values the filter matches for each of selectColumns
+func filterColumnValues(filterValue Filter) [7][]string {
+	return [7][]string{filterValue.Ptype, filterValue.V0, filterValue.V1, filterValue.V2, filterValue.V3, filterValue.V4, filterValue.V5}
+}
+
+// applyFilter adds a condition for every column the filter constrains.
+// Each column is matched with = ANY so the SQL does not depend on how many values are given.
+func (a *PgxAdapter) applyFilter(query sq.SelectBuilder, filterValue Filter) sq.SelectBuilder {
+	for i, values := range filterColumnValues(filterValue) {
+		if len(values) == 0 {
+			continue
+		}
+		if i == 0 {
+			query = query.Where(a.columns[0]+" = ANY(?)", values)
+		} else {
+			query = query.Where(a.fieldIn(i-1, values))
 		}
 	}
+	return query
+}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating rows: %w", err)
+// filterArgs returns the bitmask of columns the filter constrains and the arguments
+// applyFilter binds for them, in the same order
+func (a *PgxAdapter) filterArgs(filterValue Filter) (uint64, []any) {
+	var fields uint64
+	var args []any
+	for i, values := range filterColumnValues(filterValue) {
+		if len(values) == 0 {
+			continue
+		}
+		fields |= 1 << i
+		if i == 0 {
+			args = append(args, values)
+		} else {
+			args = append(args, a.fieldInArgs(values)...)
+		}
 	}
-
-	return nil
+	return fields, args
 }
 
 // IsFilteredCtx returns true if the loaded policy has been filtered
@@ -0,0 +1,141 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/casbin/casbin/v3/model"
+)
+
+// MutationKind identifies the adapter method making a Mutation.
+type MutationKind int
+
+const (
+	// MutationAddPolicies covers AddPolicy and AddPolicies.
+	MutationAddPolicies MutationKind = iota + 1
+	// MutationRemovePolicies covers RemovePolicy and RemovePolicies.
+	MutationRemovePolicies
+	// MutationRemoveFilteredPolicy covers RemoveFilteredPolicy.
+	MutationRemoveFilteredPolicy
+	// MutationUpdatePolicies covers UpdatePolicy and UpdatePolicies.
+	MutationUpdatePolicies
+	// MutationUpdateFilteredPolicies covers UpdateFilteredPolicies.
+	MutationUpdateFilteredPolicies
+	// MutationSavePolicy covers SavePolicy.
+	MutationSavePolicy
+)
+
+// String returns the name of the mutation kind
+func (k MutationKind) String() string {
+	switch k {
+	case MutationAddPolicies:
+		return "AddPolicies"
+	case MutationRemovePolicies:
+		return "RemovePolicies"
+	case MutationRemoveFilteredPolicy:
+		return "RemoveFilteredPolicy"
+	case MutationUpdatePolicies:
+		return "UpdatePolicies"
+	case MutationUpdateFilteredPolicies:
+		return "UpdateFilteredPolicies"
+	case MutationSavePolicy:
+		return "SavePolicy"
+	default:
+		return fmt.Sprintf("MutationKind(%d)", int(k))
+	}
+}
+
+// Mutation describes a change about to be written. Before hooks may modify its fields,
+// and the adapter writes the modified change.
+type Mutation struct {
+	Kind  MutationKind
+	Sec   string
+	Ptype string
+	// Rules holds the rules to add or remove, or the old rules of an update.
+	// Methods taking a single rule require exactly one rule to remain.
+	Rules [][]string
+	// NewRules holds the replacement rules of UpdatePolicies and UpdateFilteredPolicies.
+	NewRules [][]string
+	// FieldIndex and FieldValues hold the filter of RemoveFilteredPolicy and UpdateFilteredPolicies.
+	FieldIndex  int
+	FieldValues []string
+	// Model holds the model being saved by SavePolicy.
+	Model model.Model
+}
+
+// Interceptor wraps every mutating adapter method.
+// Before runs before the change is written and may modify the mutation or return an error to reject it.
+// After runs once the method finishes with the method's error, which it may replace.
+// After is only called for interceptors whose Before succeeded.
+type Interceptor interface {
+	Before(ctx context.Context, m *Mutation) error
+	After(ctx context.Context, m *Mutation, err error) error
+}
+
+// InterceptorFuncs implements Interceptor with optional functions.
+type InterceptorFuncs struct {
+	BeforeFunc func(ctx context.Context, m *Mutation) error
+	AfterFunc  func(ctx context.Context, m *Mutation, err error) error
+}
+
+// Before calls BeforeFunc if it is set
+func (f InterceptorFuncs) Before(ctx context.Context, m *Mutation) error {
+	if f.BeforeFunc == nil {
+		return nil
+	}
+	return f.BeforeFunc(ctx, m)
+}
+
+// After calls AfterFunc if it is set, otherwise returns err unchanged
+func (f InterceptorFuncs) After(ctx context.Context, m *Mutation, err error) error {
+	if f.AfterFunc == nil {
+		return err
+	}
+	return f.AfterFunc(ctx, m, err)
+}
+
+// WithInterceptors adds interceptors to the adapter's chain.
+// Before hooks run in the order interceptors were added and After hooks in reverse order.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(a *PgxAdapter) {
+		a.interceptors = append(a.interceptors, interceptors...)
+	}
+}
+
+// intercept runs the Before hooks for m and returns the function that runs the matching After hooks,
+// which must be called with the method's final error whether or not intercept fails.
+func (a *PgxAdapter) intercept(ctx context.Context, m *Mutation) (func(error) error, error) {
+	var ran int
+	after := func(err error) error {
+		for _, interceptor := range slices.Backward(a.interceptors[:ran]) {
+			err = interceptor.After(ctx, m, err)
+		}
+		return err
+	}
+
+	for _, interceptor := range a.interceptors {
+		if err := interceptor.Before(ctx, m); err != nil {
+			return after, err
+		}
+		ran++
+	}
+
+	return after, nil
+}
+
+// singleRule returns the only rule of a mutation made by a method taking one rule
+func (m *Mutation) singleRule() ([]string, error) {
+	if len(m.Rules) != 1 {
+		return nil, fmt.Errorf("interceptor left %d rules for a single rule %s", len(m.Rules), m.Kind)
+	}
+	return m.Rules[0], nil
+}
+
+// singleRules returns the only old and new rule of an update made by UpdatePolicy
+func (m *Mutation) singleRules() ([]string, []string, error) {
+	if len(m.Rules) != 1 || len(m.NewRules) != 1 {
+		return nil, nil, fmt.Errorf("interceptor left %d old and %d new rules for a single rule %s", len(m.Rules), len(m.NewRules), m.Kind)
+	}
+	return m.Rules[0], m.NewRules[0], nil
+}
@@ -0,0 +1,194 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+var errSuperadmin = errors.New("superadmin may only be granted by the admin service")
+
+// forbidSuperadmin rejects granting the superadmin role
+var forbidSuperadmin = pgxadapter.InterceptorFuncs{
+	BeforeFunc: func(ctx context.Context, m *pgxadapter.Mutation) error {
+		if m.Kind != pgxadapter.MutationAddPolicies || m.Ptype != "g" {
+			return nil
+		}
+		for _, rule := range m.Rules {
+			if len(rule) > 1 && rule[1] == "superadmin" {
+				return errSuperadmin
+			}
+		}
+		return nil
+	},
+}
+
+// lowercaseRules normalizes every rule value to lower case
+var lowercaseRules = pgxadapter.InterceptorFuncs{
+	BeforeFunc: func(ctx context.Context, m *pgxadapter.Mutation) error {
+		for _, rules := range [][][]string{m.Rules, m.NewRules} {
+			for _, rule := range rules {
+				for i := range rule {
+					rule[i] = strings.ToLower(rule[i])
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func TestWithInterceptors(t *testing.T) {
+	t.Run("veto", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_interceptor_veto", pgxadapter.WithInterceptors(forbidSuperadmin))
+
+		if err := adapter.AddPolicyCtx(ctx, "g", "g", []string{"alice", "superadmin"}); !errors.Is(err, errSuperadmin) {
+			t.Errorf("AddPolicyCtx() error = %v, want %v", err, errSuperadmin)
+		}
+		if err := adapter.AddPoliciesCtx(ctx, "g", "g", [][]string{{"bob", "admin"}, {"carol", "superadmin"}}); !errors.Is(err, errSuperadmin) {
+			t.Errorf("AddPoliciesCtx() error = %v, want %v", err, errSuperadmin)
+		}
+		if err := adapter.AddPolicyCtx(ctx, "g", "g", []string{"alice", "admin"}); err != nil {
+			t.Errorf("AddPolicyCtx() unexpected error: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+			t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["g"]["g"].Policy; len(got) != 1 || !slices.Equal(got[0], []string{"alice", "admin"}) {
+			t.Errorf("stored grouping rules = %v, want only [alice admin]", got)
+		}
+	})
+
+	t.Run("modify", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_interceptor_modify", pgxadapter.WithInterceptors(lowercaseRules))
+
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"Alice", "Data1", "READ"}); err != nil {
+			t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"ALICE", "data1", "read"}, []string{"alice", "DATA2", "read"}); err != nil {
+			t.Fatalf("UpdatePolicyCtx() unexpected error: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+			t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 || !slices.Equal(got[0], []string{"alice", "data2", "read"}) {
+			t.Errorf("stored rules = %v, want [[alice data2 read]]", got)
+		}
+	})
+
+	t.Run("hook_order", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var calls []string
+		record := func(name string, reject bool) pgxadapter.Interceptor {
+			return pgxadapter.InterceptorFuncs{
+				BeforeFunc: func(ctx context.Context, m *pgxadapter.Mutation) error {
+					calls = append(calls, "before "+name)
+					if reject {
+						return errSuperadmin
+					}
+					return nil
+				},
+				AfterFunc: func(ctx context.Context, m *pgxadapter.Mutation, err error) error {
+					calls = append(calls, "after "+name)
+					return err
+				},
+			}
+		}
+
+		adapter, _ := setupTestAdapter(t, "casbin_test_interceptor_order",
+			pgxadapter.WithInterceptors(record("first", false), record("second", false)),
+			pgxadapter.WithInterceptors(record("third", true)))
+
+		err := adapter.RemovePolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+		if !errors.Is(err, errSuperadmin) {
+			t.Errorf("RemovePolicyCtx() error = %v, want %v", err, errSuperadmin)
+		}
+
+		want := []string{"before first", "before second", "before third", "after second", "after first"}
+		if !slices.Equal(calls, want) {
+			t.Errorf("hook calls = %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("after_replaces_error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		errWrapped := errors.New("update rejected")
+		adapter, _ := setupTestAdapter(t, "casbin_test_interceptor_after", pgxadapter.WithInterceptors(pgxadapter.InterceptorFuncs{
+			AfterFunc: func(ctx context.Context, m *pgxadapter.Mutation, err error) error {
+				if err != nil {
+					return errors.Join(errWrapped, err)
+				}
+				return nil
+			},
+		}))
+
+		err := adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"nobody", "data1", "read"}, []string{"nobody", "data1", "write"})
+		if !errors.Is(err, errWrapped) || !errors.Is(err, pgxadapter.ErrPolicyNotFound) {
+			t.Errorf("UpdatePolicyCtx() error = %v, want both the interceptor's and ErrPolicyNotFound", err)
+		}
+	})
+
+	t.Run("kinds", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		var kinds []pgxadapter.MutationKind
+		adapter, _ := setupTestAdapter(t, "casbin_test_interceptor_kinds", pgxadapter.WithInterceptors(pgxadapter.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, m *pgxadapter.Mutation) error {
+				kinds = append(kinds, m.Kind)
+				return nil
+			},
+		}))
+
+		rule := []string{"alice", "data1", "read"}
+		m, _ := model.NewModelFromString(TestModelText)
+		steps := []func() error{
+			func() error { return adapter.AddPolicyCtx(ctx, "p", "p", rule) },
+			func() error { return adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"bob", "data2", "write"}}) },
+			func() error { return adapter.UpdatePolicyCtx(ctx, "p", "p", rule, []string{"alice", "data1", "write"}) },
+			func() error {
+				_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{rule}, 0, "alice")
+				return err
+			},
+			func() error { return adapter.RemovePolicyCtx(ctx, "p", "p", rule) },
+			func() error { return adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "bob") },
+			func() error { return adapter.SavePolicyCtx(ctx, m) },
+		}
+		for i, step := range steps {
+			if err := step(); err != nil {
+				t.Fatalf("step %d unexpected error: %v", i, err)
+			}
+		}
+
+		want := []pgxadapter.MutationKind{
+			pgxadapter.MutationAddPolicies,
+			pgxadapter.MutationAddPolicies,
+			pgxadapter.MutationUpdatePolicies,
+			pgxadapter.MutationUpdateFilteredPolicies,
+			pgxadapter.MutationRemovePolicies,
+			pgxadapter.MutationRemoveFilteredPolicy,
+			pgxadapter.MutationSavePolicy,
+		}
+		if !slices.Equal(kinds, want) {
+			t.Errorf("intercepted kinds = %v, want %v", kinds, want)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const defaultPageLimit = 100
+
+// Rule is a single stored policy rule.
+type Rule struct {
+	ID     int64
+	Ptype  string
+	Values []string
+}
+
+// Page controls keyset pagination for ListPolicies.
+// Rules are returned in ascending id order starting after AfterID.
+// A Limit of zero or less uses a default of 100.
+type Page struct {
+	AfterID int64
+	Limit   int
+}
+
+// RulePage is a page of rules returned by ListPolicies.
+type RulePage struct {
+	Rules []Rule
+	// Total is the number of rules matching the filter, ignoring pagination.
+	Total int64
+	// NextAfterID is the AfterID to request the next page with.
+	// It is zero when there are no more rules.
+	NextAfterID int64
+}
+
+// ListPolicies returns a page of rules matching the filter, ordered by id.
+func (a *PgxAdapter) ListPolicies(ctx context.Context, filter Filter, page Page) (_ *RulePage, err error) {
+	ctx, op := a.startOperation(ctx, "ListPolicies", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	filter = a.normalizeFilter(filter)
+
+	countSQL, countArgs, err := a.applyFilter(a.psql.Select("COUNT(*)").From(a.relation(filter.Ptype)), filter).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build count query: %w", err)
+	}
+
+	var total int64
+	if err := a.reader(ctx).QueryRow(ctx, countSQL, countArgs...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count policies: %w", err)
+	}
+
+	// Fetch one extra row to find out whether another page follows
+	query := a.applyFilter(a.psql.Select(append([]string{a.idColumn}, a.columns...)...).From(a.relation(filter.Ptype)), filter).
+		Where(sq.Gt{a.idColumn: page.AfterID}).
+		OrderBy(a.idColumn).
+		Limit(uint64(limit) + 1)
+
+	result := &RulePage{Total: total}
+	for rule, err := range a.queryRules(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Rules) == limit {
+			result.NextAfterID = result.Rules[limit-1].ID
+			break
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	op.setRuleCount(len(result.Rules))
+	op.setRowsAffected(int64(len(result.Rules)))
+
+	return result, nil
+}
+
+// IteratePolicies streams every rule matching the filter in id order.
+// Iteration stops at the first error, which is yielded with a zero Rule.
+// An adapter created with NewAdapterWithConn holds its connection until iteration ends,
+// so the loop body must not call the adapter.
+func (a *PgxAdapter) IteratePolicies(ctx context.Context, filter Filter) iter.Seq2[Rule, error] {
+	filter = a.normalizeFilter(filter)
+	query := a.applyFilter(a.psql.Select(append([]string{a.idColumn}, a.columns...)...).From(a.relation(filter.Ptype)), filter).
+		OrderBy(a.idColumn)
+
+	return func(yield func(Rule, error) bool) {
+		ctx, op := a.startOperation(ctx, "IteratePolicies", "", 0)
+
+		var count int
+		var err error
+		defer func() {
+			op.setRuleCount(count)
+			op.setRowsAffected(int64(count))
+			op.end(err)
+		}()
+
+		for rule, ruleErr := range a.queryRules(ctx, query) {
+			if ruleErr != nil {
+				err = ruleErr
+			} else {
+				count++
+			}
+			if !yield(rule, ruleErr) {
+				return
+			}
+		}
+	}
+}
+
+// queryRules runs a query selecting id followed by selectColumns and yields each row as a Rule.
+func (a *PgxAdapter) queryRules(ctx context.Context, query sq.SelectBuilder) iter.Seq2[Rule, error] {
+	return func(yield func(Rule, error) bool) {
+		if err := a.acquire(); err != nil {
+			yield(Rule{}, err)
+			return
+		}
+		defer a.release()
+
+		sqlQuery, args, err := query.ToSql()
+		if err != nil {
+			yield(Rule{}, fmt.Errorf("failed to build query: %w", err))
+			return
+		}
+
+		rows, err := a.reader(ctx).Query(ctx, sqlQuery, args...)
+		if err != nil {
+			yield(Rule{}, fmt.Errorf("failed to query policies: %w", err))
+			return
+		}
+		defer rows.Close() //nolint:errcheck
+
+		var id int64
+		scanner := a.newRuleScanner(&id)
+		for rows.Next() {
+			if err := rows.Scan(scanner.dest...); err != nil {
+				yield(Rule{}, fmt.Errorf("failed to scan row: %w", err))
+				return
+			}
+
+			rule := Rule{ID: id, Ptype: scanner.ptype, Values: scanner.rule()}
+
+			if !yield(rule, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(Rule{}, fmt.Errorf("error iterating rows: %w", err))
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestListPolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupPolicies [][]string
+		filter        pgxadapter.Filter
+		limit         int
+		expectedPages [][][]string
+		expectedTotal int64
+	}{
+		{
+			name: "list_all_single_page",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "bob", "data2", "write"},
+				{"g", "alice", "admin"},
+			},
+			limit: 10,
+			expectedPages: [][][]string{
+				{
+					{"alice", "data1", "read"},
+					{"bob", "data2", "write"},
+					{"alice", "admin"},
+				},
+			},
+			expectedTotal: 3,
+		},
+		{
+			name: "list_paginated",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "bob", "data2", "write"},
+				{"p", "charlie", "data3", "read"},
+			},
+			limit: 2,
+			expectedPages: [][][]string{
+				{
+					{"alice", "data1", "read"},
+					{"bob", "data2", "write"},
+				},
+				{
+					{"charlie", "data3", "read"},
+				},
+			},
+			expectedTotal: 3,
+		},
+		{
+			name: "list_filtered",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "bob", "data2", "write"},
+				{"g", "alice", "admin"},
+			},
+			filter: pgxadapter.Filter{
+				Ptype: []string{"p"},
+				V0:    []string{"alice"},
+			},
+			limit: 10,
+			expectedPages: [][][]string{
+				{
+					{"alice", "data1", "read"},
+				},
+			},
+			expectedTotal: 1,
+		},
+		{
+			name:          "list_empty",
+			setupPolicies: [][]string{},
+			limit:         10,
+			expectedPages: [][][]string{
+				nil,
+			},
+			expectedTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := fmt.Sprintf("casbin_test_list_%s", tt.name)
+			adapter, _ := setupTestAdapter(t, tableName)
+
+			for _, policy := range tt.setupPolicies {
+				if err := adapter.AddPolicy(policy[0], policy[0], policy[1:]); err != nil {
+					t.Fatalf("Failed to setup policy: %v", err)
+				}
+			}
+
+			page := pgxadapter.Page{Limit: tt.limit}
+			for i, expected := range tt.expectedPages {
+				result, err := adapter.ListPolicies(ctx, tt.filter, page)
+				if err != nil {
+					t.Fatalf("ListPolicies() unexpected error: %v", err)
+				}
+
+				if result.Total != tt.expectedTotal {
+					t.Errorf("ListPolicies() total = %d, want %d", result.Total, tt.expectedTotal)
+				}
+
+				var got [][]string
+				for _, rule := range result.Rules {
+					got = append(got, rule.Values)
+				}
+				if !slices.EqualFunc(got, expected, slices.Equal) {
+					t.Errorf("ListPolicies() page %d = %v, want %v", i, got, expected)
+				}
+
+				last := i == len(tt.expectedPages)-1
+				if last && result.NextAfterID != 0 {
+					t.Errorf("ListPolicies() page %d NextAfterID = %d, want 0", i, result.NextAfterID)
+				}
+				if !last && result.NextAfterID == 0 {
+					t.Fatalf("ListPolicies() page %d NextAfterID = 0, want next page", i)
+				}
+				page.AfterID = result.NextAfterID
+			}
+		})
+	}
+}
+
+func TestIteratePolicies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_iterate_policies"
+	adapter, _ := setupTestAdapter(t, tableName)
+
+	policies := [][]string{
+		{"p", "alice", "data1", "read"},
+		{"p", "bob", "data2", "write"},
+		{"g", "alice", "admin"},
+	}
+	for _, policy := range policies {
+		if err := adapter.AddPolicy(policy[0], policy[0], policy[1:]); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+	}
+
+	var got [][]string
+	var lastID int64
+	for rule, err := range adapter.IteratePolicies(ctx, pgxadapter.Filter{}) {
+		if err != nil {
+			t.Fatalf("IteratePolicies() unexpected error: %v", err)
+		}
+		if rule.ID <= lastID {
+			t.Errorf("IteratePolicies() id %d not after %d", rule.ID, lastID)
+		}
+		lastID = rule.ID
+		got = append(got, append([]string{rule.Ptype}, rule.Values...))
+	}
+
+	if !slices.EqualFunc(got, policies, slices.Equal) {
+		t.Errorf("IteratePolicies() = %v, want %v", got, policies)
+	}
+}
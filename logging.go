@@ -0,0 +1,309 @@
+package pgxadapter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// LogConfig controls what WithLogger records.
+// Nil fields fall back to their defaults.
+type LogConfig struct {
+	// StatementLevel is the level successful statements are logged at. Defaults to slog.LevelDebug.
+	StatementLevel slog.Leveler
+	// TxLevel is the level transaction begin, commit and rollback events are logged at.
+	// Defaults to slog.LevelDebug.
+	TxLevel slog.Leveler
+	// SlowLevel is the level statements slower than SlowQueryThreshold are logged at.
+	// Defaults to slog.LevelWarn.
+	SlowLevel slog.Leveler
+	// ErrorLevel is the level failed statements and transactions are logged at. Defaults to slog.LevelError.
+	ErrorLevel slog.Leveler
+	// SlowQueryThreshold is the duration above which a statement is logged at SlowLevel.
+	// Zero disables slow-query logging.
+	SlowQueryThreshold time.Duration
+	// Redact maps each statement argument to the value logged for it.
+	// Defaults to replacing every argument with "[redacted]", so only argument counts are revealed.
+	Redact func(arg any) any
+}
+
+// WithLogger logs every statement the adapter runs with its SQL, redacted arguments, duration
+// and rows affected, along with transaction begin, commit and rollback events.
+// Levels, redaction and the slow-query threshold are set with WithLogConfig.
+func WithLogger(logger *slog.Logger) Option {
+	return func(a *PgxAdapter) {
+		a.logger = logger
+	}
+}
+
+// WithLogConfig configures the logging enabled by WithLogger.
+func WithLogConfig(config LogConfig) Option {
+	return func(a *PgxAdapter) {
+		a.logConfig = config
+	}
+}
+
+// queryLogger writes the adapter's statement and transaction logs
+type queryLogger struct {
+	logger *slog.Logger
+	config LogConfig
+}
+
+func newQueryLogger(logger *slog.Logger, config LogConfig) *queryLogger {
+	if config.StatementLevel == nil {
+		config.StatementLevel = slog.LevelDebug
+	}
+	if config.TxLevel == nil {
+		config.TxLevel = slog.LevelDebug
+	}
+	if config.SlowLevel == nil {
+		config.SlowLevel = slog.LevelWarn
+	}
+	if config.ErrorLevel == nil {
+		config.ErrorLevel = slog.LevelError
+	}
+	if config.Redact == nil {
+		config.Redact = func(any) any { return "[redacted]" }
+	}
+
+	return &queryLogger{logger: logger, config: config}
+}
+
+// statement logs a statement that started at start
+func (l *queryLogger) statement(ctx context.Context, sql string, args []any, start time.Time, rowsAffected int64, err error, attrs ...slog.Attr) {
+	duration := time.Since(start)
+
+	level, msg := l.config.StatementLevel.Level(), "query"
+	switch {
+	case err != nil:
+		level, msg = l.config.ErrorLevel.Level(), "query failed"
+	case l.config.SlowQueryThreshold > 0 && duration >= l.config.SlowQueryThreshold:
+		level, msg = l.config.SlowLevel.Level(), "slow query"
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = l.config.Redact(arg)
+	}
+
+	attrs = append(attrs,
+		slog.String("sql", sql),
+		slog.Any("args", redacted),
+		slog.Int("arg_count", len(args)),
+		slog.Duration("duration", duration),
+		slog.Int64("rows_affected", rowsAffected),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// tx logs a transaction event
+func (l *queryLogger) tx(ctx context.Context, event string, err error, attrs ...slog.Attr) {
+	level, msg := l.config.TxLevel.Level(), "transaction "+event
+	if err != nil {
+		level, msg = l.config.ErrorLevel.Level(), "transaction "+event+" failed"
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// loggedConn logs every statement and transaction run on the wrapped pgxConn
+type loggedConn struct {
+	pgxConn
+	log *queryLogger
+}
+
+var (
+	_ pgxConn           = loggedConn{}
+	_ txOptionsBeginner = loggedConn{}
+)
+
+func (c loggedConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := c.pgxConn.Exec(ctx, sql, args...)
+	c.log.statement(ctx, sql, args, start, tag.RowsAffected(), err)
+
+	return tag, err
+}
+
+func (c loggedConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := c.pgxConn.Query(ctx, sql, args...)
+	if err != nil {
+		c.log.statement(ctx, sql, args, start, 0, err)
+		return nil, err
+	}
+
+	return &loggedRows{Rows: rows, ctx: ctx, log: c.log, sql: sql, args: args, start: start}, nil
+}
+
+func (c loggedConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &loggedRow{Row: c.pgxConn.QueryRow(ctx, sql, args...), ctx: ctx, log: c.log, sql: sql, args: args, start: time.Now()}
+}
+
+func (c loggedConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &loggedBatchResults{BatchResults: c.pgxConn.SendBatch(ctx, b), ctx: ctx, log: c.log, batch: b, start: time.Now()}
+}
+
+func (c loggedConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.BeginTx(ctx, pgx.TxOptions{})
+}
+
+func (c loggedConn) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	isolation := slog.String("isolation", string(txOptions.IsoLevel))
+
+	tx, err := beginWithOptions(ctx, c.pgxConn, txOptions)
+	c.log.tx(ctx, "begin", err, isolation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loggedTx{Tx: tx, conn: loggedConn{pgxConn: tx, log: c.log}, start: time.Now()}, nil
+}
+
+// loggedTx logs the statements run in a transaction and how it ends
+type loggedTx struct {
+	pgx.Tx
+	conn  loggedConn
+	start time.Time
+}
+
+func (t *loggedTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.conn.Exec(ctx, sql, args...)
+}
+
+func (t *loggedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.conn.Query(ctx, sql, args...)
+}
+
+func (t *loggedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.conn.QueryRow(ctx, sql, args...)
+}
+
+func (t *loggedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return t.conn.SendBatch(ctx, b)
+}
+
+func (t *loggedTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	t.conn.log.tx(ctx, "commit", err, slog.Duration("duration", time.Since(t.start)))
+	return err
+}
+
+func (t *loggedTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+	// Rolling back after a commit is the usual deferred cleanup, not an event
+	if errors.Is(err, pgx.ErrTxClosed) {
+		return err
+	}
+	t.conn.log.tx(ctx, "rollback", err, slog.Duration("duration", time.Since(t.start)))
+	return err
+}
+
+// loggedRows logs its query once the result set is closed
+type loggedRows struct {
+	pgx.Rows
+	ctx    context.Context
+	log    *queryLogger
+	sql    string
+	args   []any
+	start  time.Time
+	count  int64
+	closed bool
+}
+
+func (r *loggedRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	return false
+}
+
+func (r *loggedRows) Close() {
+	r.Rows.Close()
+	if !r.closed {
+		r.closed = true
+		r.log.statement(r.ctx, r.sql, r.args, r.start, r.count, r.Rows.Err())
+	}
+}
+
+// loggedRow logs its query once scanned
+type loggedRow struct {
+	pgx.Row
+	ctx   context.Context
+	log   *queryLogger
+	sql   string
+	args  []any
+	start time.Time
+}
+
+func (r *loggedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		r.log.statement(r.ctx, r.sql, r.args, r.start, 0, nil)
+	case err != nil:
+		r.log.statement(r.ctx, r.sql, r.args, r.start, 0, err)
+	default:
+		r.log.statement(r.ctx, r.sql, r.args, r.start, 1, nil)
+	}
+
+	return err
+}
+
+// loggedBatchResults logs a pipelined batch once its results are closed.
+// The batch is logged as its first statement along with the number of statements queued.
+type loggedBatchResults struct {
+	pgx.BatchResults
+	ctx          context.Context
+	log          *queryLogger
+	batch        *pgx.Batch
+	start        time.Time
+	rowsAffected int64
+	err          error
+	closed       bool
+}
+
+func (r *loggedBatchResults) Exec() (pgconn.CommandTag, error) {
+	tag, err := r.BatchResults.Exec()
+	r.rowsAffected += tag.RowsAffected()
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return tag, err
+}
+
+func (r *loggedBatchResults) Close() error {
+	err := r.BatchResults.Close()
+	if r.closed {
+		return err
+	}
+	r.closed = true
+
+	if r.err == nil {
+		r.err = err
+	}
+
+	var sql string
+	var args []any
+	if r.batch.Len() > 0 {
+		sql, args = r.batch.QueuedQueries[0].SQL, r.batch.QueuedQueries[0].Arguments
+	}
+	r.log.statement(r.ctx, sql, args, r.start, r.rowsAffected, r.err, slog.Int("statements", r.batch.Len()))
+
+	return err
+}
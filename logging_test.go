@@ -0,0 +1,167 @@
+package pgxadapter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// logBuffer collects JSON log records written by concurrent goroutines
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+	for line := range strings.Lines(b.buf.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to parse log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	b.buf.Reset()
+
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  pgxadapter.LogConfig
+		run     func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error
+		wantErr bool
+		// want lists the messages that must be logged, in order
+		want  []string
+		check func(t *testing.T, records []map[string]any)
+	}{
+		{
+			name: "statement_with_redacted_args",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			want: []string{"query"},
+			check: func(t *testing.T, records []map[string]any) {
+				record := records[0]
+				if sql, _ := record["sql"].(string); !strings.HasPrefix(sql, "INSERT INTO") {
+					t.Errorf("query sql = %q, want an INSERT", sql)
+				}
+				if record["arg_count"] != float64(7) || record["rows_affected"] != float64(1) {
+					t.Errorf("query arg_count = %v, rows_affected = %v, want 7 and 1", record["arg_count"], record["rows_affected"])
+				}
+				if args, _ := json.Marshal(record["args"]); strings.Contains(string(args), "alice") {
+					t.Errorf("query args = %s, want values redacted", args)
+				}
+			},
+		},
+		{
+			name: "custom_redaction",
+			config: pgxadapter.LogConfig{
+				Redact: func(arg any) any { return arg },
+			},
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			want: []string{"query"},
+			check: func(t *testing.T, records []map[string]any) {
+				if args, _ := json.Marshal(records[0]["args"]); !strings.Contains(string(args), "alice") {
+					t.Errorf("query args = %s, want values logged", args)
+				}
+			},
+		},
+		{
+			name: "slow_query",
+			config: pgxadapter.LogConfig{
+				SlowQueryThreshold: time.Nanosecond,
+			},
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			want: []string{"slow query"},
+			check: func(t *testing.T, records []map[string]any) {
+				if records[0]["level"] != "WARN" {
+					t.Errorf("slow query level = %v, want WARN", records[0]["level"])
+				}
+			},
+		},
+		{
+			name: "transaction_commit",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePoliciesCtx(ctx, "p", "p", [][]string{{"bob", "data2", "write"}}, [][]string{{"bob", "data2", "read"}})
+			},
+			want: []string{"transaction begin", "query", "transaction commit"},
+			check: func(t *testing.T, records []map[string]any) {
+				if records[1]["statements"] != float64(1) {
+					t.Errorf("batch statements = %v, want 1", records[1]["statements"])
+				}
+			},
+		},
+		{
+			name:   "transaction_rollback",
+			config: pgxadapter.LogConfig{TxLevel: slog.LevelInfo},
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePoliciesCtx(ctx, "p", "p", [][]string{{"nobody", "data2", "write"}}, [][]string{{"bob", "data2", "read"}})
+			},
+			wantErr: true,
+			want:    []string{"transaction begin", "query", "transaction rollback"},
+			check: func(t *testing.T, records []map[string]any) {
+				if records[0]["level"] != "INFO" {
+					t.Errorf("transaction begin level = %v, want INFO", records[0]["level"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_logger_" + tt.name
+
+			var logs logBuffer
+			logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			adapter, _ := setupTestAdapter(t, tableName, pgxadapter.WithLogger(logger), pgxadapter.WithLogConfig(tt.config))
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"bob", "data2", "write"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+			logs.records(t)
+
+			if err := tt.run(ctx, adapter); (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			records := logs.records(t)
+			var messages []string
+			for _, record := range records {
+				messages = append(messages, record["msg"].(string))
+			}
+			if !slices.Equal(messages, tt.want) {
+				t.Fatalf("logged %v, want %v", messages, tt.want)
+			}
+
+			tt.check(t, records)
+		})
+	}
+}
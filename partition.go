@@ -0,0 +1,320 @@
+package pgxadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PartitionStrategy selects how WithPartitioning divides the policy table.
+type PartitionStrategy int
+
+const (
+	// PartitionList stores rules in a partition per list of key values, and the rest in a
+	// default partition named after the table with a _default suffix.
+	PartitionList PartitionStrategy = iota
+	// PartitionHash spreads rules over Modulus partitions by the hash of their key, named after
+	// the table with suffixes _p0, _p1 and so on.
+	PartitionHash
+)
+
+// Partition is a list partition holding the rules whose key is one of Values.
+type Partition struct {
+	Name   string
+	Values []string
+}
+
+// Partitioning describes how the policy table is partitioned.
+type Partitioning struct {
+	Strategy PartitionStrategy
+	// Column is the partition key: ptype, or one of v0 to v5 such as a tenant or domain field.
+	Column string
+	// Partitions are created with the table by PartitionList.
+	Partitions []Partition
+	// Modulus is the number of partitions created by PartitionHash.
+	Modulus int
+}
+
+// WithPartitioning declares the default table as a partitioned table when it is created, and
+// creates its partitions. The adapter's unique index is created on each partition, so the
+// key may be any field, though only ptype can be combined with WithCaseInsensitive.
+// Tables given by WithPtypeTable and WithSectionTable are not partitioned.
+// Creating the adapter fails if Column is unknown, or if PartitionHash is given a Modulus below 1.
+func WithPartitioning(partitioning Partitioning) Option {
+	return func(a *PgxAdapter) {
+		a.partitioning = &partitioning
+	}
+}
+
+// partitionKey returns the expression the default table is partitioned by, or an error if the
+// partitioning given with WithPartitioning is invalid
+func (a *PgxAdapter) partitionKey() (string, error) {
+	if !slices.Contains(selectColumns, a.partitioning.Column) {
+		return "", fmt.Errorf("unknown column %q", a.partitioning.Column)
+	}
+	key, err := a.column(a.partitioning.Column)
+	if err != nil {
+		return "", err
+	}
+	if a.partitioning.Strategy == PartitionHash && a.partitioning.Modulus < 1 {
+		return "", fmt.Errorf("invalid hash partition modulus %d", a.partitioning.Modulus)
+	}
+	if strings.Contains(key, "[") {
+		// Array elements are expressions, which a partition key lists in parentheses
+		key = "(" + key + ")"
+	}
+	return key, nil
+}
+
+// createPartitions creates the partitions of the default table and the unique index on each
+func (a *PgxAdapter) createPartitions(ctx context.Context) error {
+	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
+
+	bounds := make(map[string]string)
+	switch a.partitioning.Strategy {
+	case PartitionList:
+		bounds[a.tableName+"_default"] = "DEFAULT"
+		for _, p := range a.partitioning.Partitions {
+			bounds[p.Name] = "FOR VALUES IN (" + quoteLiterals(p.Values) + ")"
+		}
+	case PartitionHash:
+		for i := range a.partitioning.Modulus {
+			bounds[fmt.Sprintf("%s_p%d", a.tableName, i)] = fmt.Sprintf("FOR VALUES WITH (MODULUS %d, REMAINDER %d)", a.partitioning.Modulus, i)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(bounds)) {
+		createSQL := `CREATE TABLE IF NOT EXISTS ` + pgx.Identifier{name}.Sanitize() +
+			` PARTITION OF ` + quotedTableName + ` ` + bounds[name]
+		if _, err := a.db.Exec(ctx, createSQL); err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+		if err := a.createPartitionIndex(ctx, a.db, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createPartitionIndex creates the adapter's unique index on a partition
+func (a *PgxAdapter) createPartitionIndex(ctx context.Context, q pgxConn, partition string) error {
+	indexName, indexColumns := a.uniqueIndex(partition)
+	createIndexSQL := `CREATE UNIQUE INDEX IF NOT EXISTS ` + pgx.Identifier{indexName}.Sanitize() +
+		` ON ` + pgx.Identifier{partition}.Sanitize() + `(` + indexColumns + `)`
+	if _, err := q.Exec(ctx, createIndexSQL); err != nil {
+		return fmt.Errorf("failed to create index on partition %s: %w", partition, err)
+	}
+	return nil
+}
+
+// AttachPartition creates a list partition called name holding the rules whose partition key
+// is one of values, as when onboarding a tenant. Rules with those keys already stored in the
+// default partition are moved into it. The work is split into short transactions so the default
+// partition is never scanned under an exclusive lock:
+//
+//  1. the partition is built as a standalone table with a constraint matching its bound
+//  2. a NOT VALID constraint excluding the bound is added to the default partition, which
+//     briefly locks it without a scan; from then on the tenant's rules cannot be added
+//  3. the tenant's rules are moved out of the default partition
+//  4. the constraint is validated, which scans the default partition without blocking reads or writes
+//  5. the partition is attached, which briefly locks the default partition without a scan
+//
+// Between steps 2 and 5, adding a rule with one of values fails with a check violation, and
+// from step 3 until the partition is attached the tenant's rules are not loaded. If a step
+// fails, the moved rules are returned to the default partition and the table is dropped.
+// It returns ErrNotListPartitioned unless the adapter was created with PartitionList.
+func (a *PgxAdapter) AttachPartition(ctx context.Context, name string, values ...string) (err error) {
+	ctx, op := a.startOperation(ctx, "AttachPartition", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	if a.partitioning == nil || a.partitioning.Strategy != PartitionList {
+		return ErrNotListPartitioned
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("failed to attach partition %s: no values given", name)
+	}
+
+	key, err := a.partitionKey()
+	if err != nil {
+		return fmt.Errorf("failed to attach partition %s: %w", name, err)
+	}
+	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
+	quotedName := pgx.Identifier{name}.Sanitize()
+	quotedCheckName := pgx.Identifier{name + "_bound"}.Sanitize()
+	quotedExcludeName := pgx.Identifier{name + "_excluded"}.Sanitize()
+
+	var defaultPartition *string
+	if err := a.db.QueryRow(ctx, `SELECT NULLIF(partdefid, 0)::regclass::text FROM pg_partitioned_table WHERE partrelid = $1::regclass`,
+		quotedTableName).Scan(&defaultPartition); err != nil {
+		return fmt.Errorf("failed to attach partition %s: %w", name, err)
+	}
+
+	// A constraint matching the partition bound lets ATTACH skip scanning the new partition
+	err = a.execPartitionTx(ctx, name,
+		`CREATE TABLE `+quotedName+` (LIKE `+quotedTableName+` INCLUDING DEFAULTS)`,
+		`ALTER TABLE `+quotedName+` ADD CONSTRAINT `+quotedCheckName+
+			` CHECK (`+key+` IS NOT NULL AND `+key+` IN (`+quoteLiterals(values)+`))`,
+	)
+	if err != nil {
+		return err
+	}
+	if err := a.createPartitionIndex(ctx, a.db, name); err != nil {
+		return errors.Join(err, a.abortAttach(ctx, name, defaultPartition))
+	}
+
+	attach := []string{
+		`ALTER TABLE ` + quotedTableName + ` ATTACH PARTITION ` + quotedName + ` FOR VALUES IN (` + quoteLiterals(values) + `)`,
+		`ALTER TABLE ` + quotedName + ` DROP CONSTRAINT ` + quotedCheckName,
+	}
+	if defaultPartition != nil {
+		// Without a valid constraint excluding the new bound, ATTACH would scan the default
+		// partition under an ACCESS EXCLUSIVE lock. Each statement runs in its own transaction,
+		// so the lock taken to add the constraint is released before the scans.
+		steps := []struct {
+			sql  string
+			args []any
+		}{
+			{sql: `ALTER TABLE ` + *defaultPartition + ` ADD CONSTRAINT ` + quotedExcludeName +
+				` CHECK (NOT (` + key + ` IS NOT NULL AND ` + key + ` IN (` + quoteLiterals(values) + `))) NOT VALID`},
+			{sql: `WITH moved AS (DELETE FROM ` + *defaultPartition + ` WHERE ` + key + ` = ANY($1) RETURNING *)
+				INSERT INTO ` + quotedName + ` SELECT * FROM moved`, args: []any{values}},
+			{sql: `ALTER TABLE ` + *defaultPartition + ` VALIDATE CONSTRAINT ` + quotedExcludeName},
+		}
+		for _, step := range steps {
+			if _, err := a.db.Exec(ctx, step.sql, step.args...); err != nil {
+				err = fmt.Errorf("failed to attach partition %s: %w", name, err)
+				return errors.Join(err, a.abortAttach(ctx, name, defaultPartition))
+			}
+		}
+		attach = append(attach, `ALTER TABLE `+*defaultPartition+` DROP CONSTRAINT `+quotedExcludeName)
+	}
+
+	if err := a.execPartitionTx(ctx, name, attach...); err != nil {
+		return errors.Join(err, a.abortAttach(ctx, name, defaultPartition))
+	}
+
+	return nil
+}
+
+// execPartitionTx runs statements in a single transaction on behalf of AttachPartition
+func (a *PgxAdapter) execPartitionTx(ctx context.Context, name string, statements ...string) error {
+	tx, err := beginWithOptions(ctx, a.db, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to attach partition %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// abortAttach undoes a failed AttachPartition: it drops the default partition's exclusion
+// constraint, returns any moved rules to the default partition and drops the standalone table.
+// Nothing is undone if the table was attached after all, as when only the commit reply was lost.
+func (a *PgxAdapter) abortAttach(ctx context.Context, name string, defaultPartition *string) error {
+	// The caller's context may be what failed the attach, so cleaning up does not depend on it
+	ctx = context.WithoutCancel(ctx)
+	quotedName := pgx.Identifier{name}.Sanitize()
+
+	tx, err := beginWithOptions(ctx, a.db, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to undo attaching partition %s: %w", name, err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var attached *bool
+	if err := tx.QueryRow(ctx, `SELECT relispartition FROM pg_class WHERE oid = to_regclass($1)`,
+		quotedName).Scan(&attached); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to undo attaching partition %s: %w", name, err)
+	}
+	if attached == nil || *attached {
+		return nil
+	}
+
+	var statements []string
+	if defaultPartition != nil {
+		statements = append(statements,
+			`ALTER TABLE `+*defaultPartition+` DROP CONSTRAINT IF EXISTS `+pgx.Identifier{name + "_excluded"}.Sanitize(),
+			`INSERT INTO `+*defaultPartition+` SELECT * FROM `+quotedName,
+		)
+	}
+	statements = append(statements, `DROP TABLE `+quotedName)
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to undo attaching partition %s: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to undo attaching partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// DetachPartition detaches the partition called name, as when offboarding a tenant. The
+// detached table keeps its rules, which the adapter no longer reads; drop it to delete them.
+// Without a default partition it is detached concurrently, so reads and writes are not
+// blocked; otherwise Postgres requires a brief exclusive lock on the table.
+// It returns ErrNotListPartitioned unless the adapter was created with PartitionList.
+func (a *PgxAdapter) DetachPartition(ctx context.Context, name string) (err error) {
+	ctx, op := a.startOperation(ctx, "DetachPartition", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	if a.partitioning == nil || a.partitioning.Strategy != PartitionList {
+		return ErrNotListPartitioned
+	}
+
+	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
+
+	var hasDefault bool
+	if err := a.db.QueryRow(ctx, `SELECT partdefid <> 0 FROM pg_partitioned_table WHERE partrelid = $1::regclass`,
+		quotedTableName).Scan(&hasDefault); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+
+	// DETACH CONCURRENTLY cannot run inside a transaction, which Exec on its own does not open
+	detachSQL := `ALTER TABLE ` + quotedTableName + ` DETACH PARTITION ` + pgx.Identifier{name}.Sanitize()
+	if !hasDefault {
+		detachSQL += ` CONCURRENTLY`
+	}
+	if _, err := a.db.Exec(ctx, detachSQL); err != nil {
+		return fmt.Errorf("failed to detach partition %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// quoteLiterals returns values as a comma separated list of SQL string literals
+func quoteLiterals(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
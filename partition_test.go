@@ -0,0 +1,205 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// loadRules loads every rule through the adapter and returns the p rules sorted
+func loadRules(t *testing.T, adapter *pgxadapter.PgxAdapter) [][]string {
+	t.Helper()
+
+	m, _ := model.NewModelFromString(TestModelText)
+	if err := adapter.LoadPolicyCtx(context.Background(), m); err != nil {
+		t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+	}
+	got := m["p"]["p"].Policy
+	slices.SortFunc(got, slices.Compare)
+	return got
+}
+
+func TestWithPartitioning(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_partition_list"
+		adapter, _ := setupTestAdapter(t, tableName, pgxadapter.WithPartitioning(pgxadapter.Partitioning{
+			Strategy:   pgxadapter.PartitionList,
+			Column:     "v0",
+			Partitions: []pgxadapter.Partition{{Name: tableName + "_alice", Values: []string{"alice"}}},
+		}))
+		pool := adapter.GetPool()
+
+		detached := pgx.Identifier{tableName + "_bob"}.Sanitize()
+		_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+detached)
+		t.Cleanup(func() {
+			_, _ = pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+detached)
+		})
+
+		rules := [][]string{{"alice", "data1", "read"}, {"alice", "data1", "read"}, {"bob", "data2", "write"}}
+		if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+			t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+		}
+
+		var count int
+		if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+tableName+"_alice").Scan(&count); err != nil {
+			t.Fatalf("Failed to count partition rules: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("alice partition holds %d rules, want 1", count)
+		}
+
+		// Onboarding moves bob's rules out of the default partition
+		if err := adapter.AttachPartition(ctx, tableName+"_bob", "bob"); err != nil {
+			t.Fatalf("AttachPartition() unexpected error: %v", err)
+		}
+		if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+tableName+"_default").Scan(&count); err != nil {
+			t.Fatalf("Failed to count default partition rules: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("default partition holds %d rules, want 0", count)
+		}
+		if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM pg_constraint WHERE conrelid = $1::regclass AND contype = 'c'",
+			tableName+"_default").Scan(&count); err != nil {
+			t.Fatalf("Failed to count default partition constraints: %v", err)
+		}
+		if count != 0 {
+			t.Errorf("default partition keeps %d check constraints, want the temporary one dropped", count)
+		}
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"bob", "data2", "write"}); err != nil {
+			t.Fatalf("AddPolicyCtx() duplicate unexpected error: %v", err)
+		}
+
+		want := [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}
+		if got := loadRules(t, adapter); !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("loaded rules = %v, want %v", got, want)
+		}
+
+		// Offboarding leaves bob's rules in the detached table
+		if err := adapter.DetachPartition(ctx, tableName+"_bob"); err != nil {
+			t.Fatalf("DetachPartition() unexpected error: %v", err)
+		}
+		want = [][]string{{"alice", "data1", "read"}}
+		if got := loadRules(t, adapter); !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("loaded rules after detach = %v, want %v", got, want)
+		}
+
+		// A failed attach moves the rules back and drops the table
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"carol", "data3", "read"}); err != nil {
+			t.Fatalf("Failed to setup policy: %v", err)
+		}
+		if err := adapter.AttachPartition(ctx, tableName+"_overlap", "alice", "carol"); err == nil {
+			t.Error("AttachPartition() expected an error for values overlapping a partition")
+		}
+		want = [][]string{{"alice", "data1", "read"}, {"carol", "data3", "read"}}
+		if got := loadRules(t, adapter); !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("loaded rules after failed attach = %v, want %v", got, want)
+		}
+		var exists bool
+		if err := pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", tableName+"_overlap").Scan(&exists); err != nil {
+			t.Fatalf("Failed to look up table: %v", err)
+		}
+		if exists {
+			t.Error("AttachPartition() left the table of a failed attach behind")
+		}
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_partition_hash"
+		adapter, _ := setupTestAdapter(t, tableName, pgxadapter.WithPartitioning(pgxadapter.Partitioning{
+			Strategy: pgxadapter.PartitionHash,
+			Column:   "v0",
+			Modulus:  4,
+		}))
+
+		var partitions int
+		if err := adapter.GetPool().QueryRow(ctx, "SELECT COUNT(*) FROM pg_inherits WHERE inhparent = $1::regclass", tableName).Scan(&partitions); err != nil {
+			t.Fatalf("Failed to count partitions: %v", err)
+		}
+		if partitions != 4 {
+			t.Errorf("created %d partitions, want 4", partitions)
+		}
+
+		rules := [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}, {"carol", "data3", "read"}}
+		if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+			t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+		}
+		if err := adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "bob"); err != nil {
+			t.Fatalf("RemoveFilteredPolicyCtx() unexpected error: %v", err)
+		}
+
+		want := [][]string{{"alice", "data1", "read"}, {"carol", "data3", "read"}}
+		if got := loadRules(t, adapter); !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("loaded rules = %v, want %v", got, want)
+		}
+
+		stats, err := adapter.Stats(ctx, pgxadapter.Filter{})
+		if err != nil {
+			t.Fatalf("Stats() unexpected error: %v", err)
+		}
+		if stats.TableSize == 0 || len(stats.Indexes) == 0 {
+			t.Errorf("Stats() = %+v, want the partitions' size and indexes", stats)
+		}
+
+		if err := adapter.AttachPartition(ctx, tableName+"_extra", "dave"); !errors.Is(err, pgxadapter.ErrNotListPartitioned) {
+			t.Errorf("AttachPartition() error = %v, want ErrNotListPartitioned", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		bootstrap, _ := setupTestAdapter(t, "casbin_test_partition_bootstrap")
+		tests := []struct {
+			name         string
+			partitioning pgxadapter.Partitioning
+		}{
+			{name: "unknown_column", partitioning: pgxadapter.Partitioning{Strategy: pgxadapter.PartitionList, Column: "tenant"}},
+			{name: "id_column", partitioning: pgxadapter.Partitioning{Strategy: pgxadapter.PartitionList, Column: "id"}},
+			{name: "hash_without_modulus", partitioning: pgxadapter.Partitioning{Strategy: pgxadapter.PartitionHash, Column: "v0"}},
+		}
+		for _, tt := range tests {
+			_, err := pgxadapter.NewAdapterWithPool(bootstrap.GetPool(),
+				pgxadapter.WithTableName("casbin_test_partition_"+tt.name),
+				pgxadapter.WithPartitioning(tt.partitioning))
+			if err == nil {
+				t.Errorf("%s: NewAdapterWithPool() expected an error", tt.name)
+			}
+		}
+	})
+
+	t.Run("case_insensitive", func(t *testing.T) {
+		t.Parallel()
+
+		bootstrap, _ := setupTestAdapter(t, "casbin_test_partition_ci_bootstrap")
+		_, err := pgxadapter.NewAdapterWithPool(bootstrap.GetPool(),
+			pgxadapter.WithTableName("casbin_test_partition_ci_v0"),
+			pgxadapter.WithCaseInsensitive(),
+			pgxadapter.WithPartitioning(pgxadapter.Partitioning{Strategy: pgxadapter.PartitionHash, Column: "v0", Modulus: 2}))
+		if err == nil {
+			t.Error("NewAdapterWithPool() expected an error combining WithCaseInsensitive and a v0 partition key")
+		}
+
+		// ptype is matched exactly, so partitioning by it keeps rules differing in case together
+		adapter, _ := setupTestAdapter(t, "casbin_test_partition_ci_ptype",
+			pgxadapter.WithCaseInsensitive(),
+			pgxadapter.WithPartitioning(pgxadapter.Partitioning{Strategy: pgxadapter.PartitionHash, Column: "ptype", Modulus: 2}))
+		rules := [][]string{{"Alice", "data1", "read"}, {"alice", "data1", "read"}}
+		if err := adapter.AddPoliciesCtx(context.Background(), "p", "p", rules); err != nil {
+			t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+		}
+		if got := loadRules(t, adapter); len(got) != 1 {
+			t.Errorf("loaded rules = %v, want a single rule", got)
+		}
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/casbin/casbin/v3/model"
 	"github.com/casbin/casbin/v3/persist"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,21 +21,106 @@ var _ persist.Adapter = (*PgxAdapter)(nil)
 const (
 	defaultTableName = "casbin_rule"
 	defaultDatabase  = "casbin"
+
+	// defaultBatchSize is the number of rules inserted per statement
+	defaultBatchSize = 1000
+	// maxBatchSize keeps a multi-row insert within the Postgres limit of 65535 bind parameters
+	maxBatchSize = 65535 / 7
 )
 
 // PgxAdapter represents the pgx adapter for policy persistence
 type PgxAdapter struct {
-	db         *sql.DB
+	db         pgxConn
+	readDB     pgxConn
 	pool       *pgxpool.Pool
+	conn       *pgx.Conn
 	tableName  string
 	database   string
 	psql       sq.StatementBuilderType
 	isFiltered bool
 	indexes    [][]string
+	strict     bool
+	batchSize  int
+	stmts      statementCache
 	mu         sync.RWMutex
 
+	// columns holds the quoted names of the table's ptype and value columns in selectColumns order,
+	// and idColumn the quoted name of its id column, as mapped by WithColumnMapping
+	columnMapping   ColumnMapping
+	columns         []string
+	idColumn        string
+	skipCreateTable bool
+
+	// caseInsensitive is set by WithCaseInsensitive
+	caseInsensitive bool
+
+	// arrayStorage is set by WithArrayStorage; columns then holds the ptype and array columns
+	arrayStorage bool
+
+	// ptypeTables and sectionTables route rules to tables other than tableName,
+	// as given by WithPtypeTable and WithSectionTable
+	ptypeTables   map[string]string
+	sectionTables map[string]string
+
+	// partitioning is nil unless WithPartitioning is provided
+	partitioning *Partitioning
+
+	// statsLastModified is set by WithStatsLastModified
+	statsLastModified bool
+
+	// telemetry is nil unless WithTelemetry is provided
+	telemetry *telemetry
+
+	// model and modelText are given by WithModel and WithModelText and reduced to shapes by setup;
+	// shapes is nil when rules are not checked against a model
+	model     model.Model
+	modelText string
+	shapes    map[string]ruleShape
+
+	// columnLengths holds the VARCHAR lengths given by WithColumnLength and WithTextColumns, 0 meaning TEXT;
+	// columnLimits holds the lengths the table's columns allow, in selectColumns order, 0 meaning unlimited
+	columnLengths map[string]int
+	columnLimits  [7]int
+
+	// fieldRules holds the per-field normalizers and validators given by WithFieldRule, keyed by ptype
+	fieldRules map[string]*[6]FieldRule
+
+	// interceptors wrap every mutating method
+	interceptors []Interceptor
+
+	// logger is nil unless WithLogger is provided
+	logger    *slog.Logger
+	logConfig LogConfig
+
+	// retryPolicy is nil unless WithRetry is provided
+	retryPolicy *RetryPolicy
+
+	// transaction configuration
+	isoLevels map[OperationClass]pgx.TxIsoLevel
+	writeLock LockMode
+
 	// pool configuration
-	usePool bool
+	usePool  bool
+	ownsPool bool
+
+	// replica serves read-only queries when configured with WithReadReplica
+	replica        *pgxpool.Pool
+	replicaConnStr string
+	ownsReplica    bool
+
+	// dbPool is the pool statements run on, which GetDB wraps; nil with NewAdapterWithConn
+	dbPool *pgxpool.Pool
+
+	// sqlDB is opened lazily by GetDB
+	sqlDBMu sync.Mutex
+	sqlDB   *sql.DB
+
+	// lifecycle
+	lifecycleMu sync.RWMutex
+	closed      bool
+	inflight    sync.WaitGroup
+	releaseOnce sync.Once
+	releaseErr  error
 }
 
 // Option is a function that configures the adapter
@@ -54,7 +141,7 @@ func WithDatabaseName(database string) Option {
 }
 
 // WithIndex adds a composite index on the specified columns.
-// Valid columns are: ptype, v0, v1, v2, v3, v4, v5.
+// Valid columns are: id, ptype, v0, v1, v2, v3, v4, v5, which refer to the mapped columns with WithColumnMapping.
 // Can be called multiple times to add multiple indexes.
 func WithIndex(columns ...string) Option {
 	return func(a *PgxAdapter) {
@@ -64,8 +151,28 @@ func WithIndex(columns ...string) Option {
 	}
 }
 
-// WithPool configures the adapter to use a connection pool instead of a single connection.
-// Pool settings can be configured via connection string parameters (e.g., pool_max_conns, pool_min_conns).
+// WithStrict makes mutations fail instead of silently succeeding when they would not change storage.
+// Adding a rule that already exists returns ErrPolicyExists and removing a rule that does not exist
+// returns ErrPolicyNotFound. Batch operations are rolled back if any rule fails the check.
+func WithStrict() Option {
+	return func(a *PgxAdapter) {
+		a.strict = true
+	}
+}
+
+// WithBatchSize sets the maximum number of rules inserted by a single statement.
+// Larger batches are split into chunks of this size inside one transaction.
+// Values are capped at 9362, the most rules whose parameters fit in one statement. The default is 1000.
+func WithBatchSize(size int) Option {
+	return func(a *PgxAdapter) {
+		if size > 0 {
+			a.batchSize = min(size, maxBatchSize)
+		}
+	}
+}
+
+// WithPool makes NewAdapter configure its connection pool from the connection string, so pool
+// settings can be given as parameters (e.g., pool_max_conns, pool_min_conns).
 func WithPool() Option {
 	return func(a *PgxAdapter) {
 		a.usePool = true
@@ -73,7 +180,9 @@ func WithPool() Option {
 }
 
 // NewAdapter creates a new adapter with a connection string.
-// If WithPool is provided, a connection pool is created. Otherwise, a single connection is used.
+// The adapter always owns a connection pool. If WithPool is provided, the pool is configured from
+// the connection string, including its pool_ parameters. Otherwise, the string is parsed as a
+// pgx.ConnConfig and passed to NewAdapterWithConfig, which uses the default pool settings.
 func NewAdapter(connStr string, opts ...Option) (*PgxAdapter, error) {
 	ctx := context.Background()
 
@@ -94,7 +203,14 @@ func NewAdapter(connStr string, opts ...Option) (*PgxAdapter, error) {
 			return nil, fmt.Errorf("failed to ping database: %w", err)
 		}
 
-		return NewAdapterWithPool(pool, opts...)
+		a, err := NewAdapterWithPool(pool, opts...)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		a.ownsPool = true
+
+		return a, nil
 	}
 
 	config, err := pgx.ParseConfig(connStr)
@@ -106,43 +222,66 @@ func NewAdapter(connStr string, opts ...Option) (*PgxAdapter, error) {
 }
 
 // NewAdapterWithConfig creates a new adapter with a given pgx.ConnConfig.
+// Connections are opened on demand from a pool owned by the adapter and released by Close.
 func NewAdapterWithConfig(config *pgx.ConnConfig, opts ...Option) (*PgxAdapter, error) {
-	db := stdlib.OpenDB(*config)
-
-	a := &PgxAdapter{
-		db:        db,
-		tableName: defaultTableName,
-		database:  defaultDatabase,
-		psql:      sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	poolConfig, err := pgxpool.ParseConfig("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool config: %w", err)
 	}
+	poolConfig.ConnConfig = config.Copy()
 
-	// Apply options
-	for _, opt := range opts {
-		opt(a)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	// Create table if it doesn't exist
-	if err := a.createTable(); err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
+	a := newAdapter(pool, opts...)
+	a.dbPool = pool
+	a.ownsPool = true
+
+	if err := a.setup(); err != nil {
+		pool.Close()
+		return nil, err
 	}
 
 	return a, nil
-
 }
 
-// NewAdapterWithConn creates a new adapter with an existing connection.
-// The connection's config is extracted to create a *sql.DB via stdlib.OpenDB.
-// The passed connection is closed after extracting its config.
+// NewAdapterWithConn creates a new adapter that runs every operation on an existing connection.
+// A pgx.Conn is not safe for concurrent use, so operations are serialized: each statement, result set
+// and transaction holds the connection exclusively until it completes.
+// The connection stays owned by the caller and is not closed by Close.
 func NewAdapterWithConn(conn *pgx.Conn, opts ...Option) (*PgxAdapter, error) {
-	connConfig := conn.Config()
-	conn.Close(context.Background())
+	a := newAdapter(&lockedConn{conn: conn}, opts...)
+	a.conn = conn
+
+	if err := a.setup(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// NewAdapterWithPool creates a new adapter with an existing connection pool
+func NewAdapterWithPool(pool *pgxpool.Pool, opts ...Option) (*PgxAdapter, error) {
+	a := newAdapter(pool, opts...)
+	a.pool = pool
+	a.dbPool = pool
+
+	if err := a.setup(); err != nil {
+		return nil, err
+	}
 
-	db := stdlib.OpenDB(*connConfig)
+	return a, nil
+}
 
+// newAdapter creates an adapter executing on db with the defaults and options applied
+func newAdapter(db pgxConn, opts ...Option) *PgxAdapter {
 	a := &PgxAdapter{
 		db:        db,
 		tableName: defaultTableName,
 		database:  defaultDatabase,
+		batchSize: defaultBatchSize,
 		psql:      sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
 	}
 
@@ -150,73 +289,144 @@ func NewAdapterWithConn(conn *pgx.Conn, opts ...Option) (*PgxAdapter, error) {
 	for _, opt := range opts {
 		opt(a)
 	}
+	a.resolveColumns()
 
-	// Create table if it doesn't exist
-	if err := a.createTable(); err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
+	return a
+}
+
+// setup opens the read replica, if one is configured, wraps the connections for logging
+// and creates the table if it doesn't exist
+func (a *PgxAdapter) setup() error {
+	if a.arrayStorage && a.caseInsensitive {
+		return fmt.Errorf("WithArrayStorage cannot be combined with WithCaseInsensitive")
+	}
+	if a.caseInsensitive && a.partitioning != nil && a.partitioning.Column != "ptype" {
+		// Rules are routed by their raw value, so spellings differing in case would reach partitions
+		// whose unique indexes never compare them
+		return fmt.Errorf("WithCaseInsensitive cannot be combined with WithPartitioning on a value column")
 	}
 
-	return a, nil
-}
+	if err := a.loadModel(); err != nil {
+		return err
+	}
 
-// NewAdapterWithPool creates a new adapter with an existing connection pool
-func NewAdapterWithPool(pool *pgxpool.Pool, opts ...Option) (*PgxAdapter, error) {
-	db := stdlib.OpenDBFromPool(pool)
+	if err := a.openReplica(); err != nil {
+		return err
+	}
 
-	a := &PgxAdapter{
-		db:        db,
-		pool:      pool,
-		tableName: defaultTableName,
-		database:  defaultDatabase,
-		psql:      sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	a.readDB = a.db
+	if a.replica != nil {
+		a.readDB = a.replica
 	}
 
-	// Apply options
-	for _, opt := range opts {
-		opt(a)
+	if a.logger != nil {
+		log := newQueryLogger(a.logger, a.logConfig)
+		a.db = loggedConn{pgxConn: a.db, log: log}
+		a.readDB = loggedConn{pgxConn: a.readDB, log: log}
 	}
 
-	// Create table if it doesn't exist
 	if err := a.createTable(); err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
+		if a.ownsReplica {
+			a.replica.Close()
+		}
+		return fmt.Errorf("failed to create table: %w", err)
 	}
 
-	return a, nil
+	return nil
 }
 
-// createTable creates the casbin_rule table if it doesn't exist
+// createTable creates the casbin_rule table and any tables given by WithPtypeTable and WithSectionTable
+// if they don't exist, unless WithoutCreateTable is given, and reads the lengths their columns allow
 func (a *PgxAdapter) createTable() error {
 	ctx := context.Background()
 
+	if a.skipCreateTable {
+		return a.loadColumnLengths(ctx)
+	}
+
+	idDef := a.idColumn + " SERIAL PRIMARY KEY"
+	if a.partitioning != nil {
+		// A primary key on a partitioned table must include the partition key, so id is indexed instead
+		idDef = a.idColumn + " SERIAL NOT NULL"
+	}
+	for i, table := range a.tables() {
+		if i == 1 {
+			var err error
+			if idDef, err = a.idDefault(ctx); err != nil {
+				return err
+			}
+		}
+		if err := a.createRuleTable(ctx, table, idDef); err != nil {
+			return err
+		}
+	}
+
+	return a.loadColumnLengths(ctx)
+}
+
+// createRuleTable creates a single table with its id column defined by idDef, and its indexes
+func (a *PgxAdapter) createRuleTable(ctx context.Context, table, idDef string) error {
 	// Use pgx identifier quoting for secure table name handling
-	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
-	quotedIndexName := pgx.Identifier{"idx_" + a.tableName}.Sanitize()
+	quotedTableName := pgx.Identifier{table}.Sanitize()
+	indexName, indexColumns := a.uniqueIndex(table)
+	quotedIndexName := pgx.Identifier{indexName}.Sanitize()
 
+	columnDefs := []string{idDef, a.columns[0] + " " + a.columnType("ptype") + " NOT NULL"}
+	if a.arrayStorage {
+		columnDefs = append(columnDefs, a.arrayColumn()+" TEXT[] NOT NULL DEFAULT '{}'")
+	} else {
+		for i, col := range a.columns[1:] {
+			columnDefs = append(columnDefs, col+" "+a.columnType(selectColumns[i+1]))
+		}
+	}
 	createTableSQL := `CREATE TABLE IF NOT EXISTS ` + quotedTableName + ` (
-		id SERIAL PRIMARY KEY,
-		ptype VARCHAR(100) NOT NULL,
-		v0 VARCHAR(100),
-		v1 VARCHAR(100),
-		v2 VARCHAR(100),
-		v3 VARCHAR(100),
-		v4 VARCHAR(100),
-		v5 VARCHAR(100)
+		` + strings.Join(columnDefs, ",\n\t\t") + `
 	)`
 
 	createIndexSQL := `CREATE UNIQUE INDEX IF NOT EXISTS ` + quotedIndexName + `
-		ON ` + quotedTableName + `(ptype, COALESCE(v0,''), COALESCE(v1,''), COALESCE(v2,''), COALESCE(v3,''), COALESCE(v4,''), COALESCE(v5,''))`
+		ON ` + quotedTableName + `(` + indexColumns + `)`
+
+	partitioned := a.partitioning != nil && table == a.tableName
+	if partitioned {
+		key, err := a.partitionKey()
+		if err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+		strategy := "LIST"
+		if a.partitioning.Strategy == PartitionHash {
+			strategy = "HASH"
+		}
+		createTableSQL += ` PARTITION BY ` + strategy + ` (` + key + `)`
+
+		// The unique index is created on each partition instead, so the key need not be part of it
+		createIndexSQL = `CREATE INDEX IF NOT EXISTS ` + pgx.Identifier{"idx_" + table + "_id"}.Sanitize() +
+			` ON ` + quotedTableName + `(` + a.idColumn + `)`
+	}
 
 	// Execute creation statements
-	if _, err := a.db.ExecContext(ctx, createTableSQL); err != nil {
+	if _, err := a.db.Exec(ctx, createTableSQL); err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
 	}
-	if _, err := a.db.ExecContext(ctx, createIndexSQL); err != nil {
+	if _, err := a.db.Exec(ctx, createIndexSQL); err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
+	if partitioned {
+		if err := a.createPartitions(ctx); err != nil {
+			return err
+		}
+	}
+	if a.arrayStorage {
+		quotedGinName := pgx.Identifier{"idx_" + table + "_gin"}.Sanitize()
+		createGinSQL := `CREATE INDEX IF NOT EXISTS ` + quotedGinName +
+			` ON ` + quotedTableName + ` USING GIN (` + a.arrayColumn() + `)`
+		if _, err := a.db.Exec(ctx, createGinSQL); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
 
 	// Create custom indexes
 	for _, columns := range a.indexes {
-		if err := a.createIndex(ctx, columns); err != nil {
+		if err := a.createIndex(ctx, table, columns); err != nil {
 			return err
 		}
 	}
@@ -224,41 +434,132 @@ func (a *PgxAdapter) createTable() error {
 	return nil
 }
 
-func (a *PgxAdapter) createIndex(ctx context.Context, columns []string) error {
-	quotedTableName := pgx.Identifier{a.tableName}.Sanitize()
-	indexName := "idx_" + a.tableName + "_" + strings.Join(columns, "_")
+func (a *PgxAdapter) createIndex(ctx context.Context, table string, columns []string) error {
+	quotedTableName := pgx.Identifier{table}.Sanitize()
+	indexName := "idx_" + table + "_" + strings.Join(columns, "_")
 	quotedIndexName := pgx.Identifier{indexName}.Sanitize()
 
 	var quotedColumns []string
-	for _, col := range columns {
-		quotedColumns = append(quotedColumns, pgx.Identifier{col}.Sanitize())
+	for _, name := range columns {
+		col, err := a.column(name)
+		if err != nil {
+			return fmt.Errorf("failed to create index %s: %w", indexName, err)
+		}
+		if strings.Contains(col, "[") {
+			// Array elements are expressions, which an index lists in parentheses
+			col = "(" + col + ")"
+		}
+		quotedColumns = append(quotedColumns, col)
 	}
 
 	createIndexSQL := `CREATE INDEX IF NOT EXISTS ` + quotedIndexName +
 		` ON ` + quotedTableName + `(` + strings.Join(quotedColumns, ", ") + `)`
 
-	if _, err := a.db.ExecContext(ctx, createIndexSQL); err != nil {
+	if _, err := a.db.Exec(ctx, createIndexSQL); err != nil {
 		return fmt.Errorf("failed to create index %s: %w", indexName, err)
 	}
 
 	return nil
 }
 
-// GetConn is deprecated. With the stdlib bridge, connections are managed by *sql.DB.
-// Returns nil.
-func (a *PgxAdapter) GetConn() *pgx.Conn {
+// Close waits for in-flight operations to finish, then releases the resources the adapter owns:
+// the *sql.DB returned by GetDB, the connection pool opened by NewAdapter or NewAdapterWithConfig
+// and the read replica pool opened by WithReadReplicaConnString.
+// A pool passed to NewAdapterWithPool or a connection passed to NewAdapterWithConn is left open
+// for the caller to close.
+// Operations started after Close return ErrClosed. If ctx ends before in-flight operations
+// finish, Close returns the context's error without releasing anything; it may be called again.
+func (a *PgxAdapter) Close(ctx context.Context) error {
+	a.lifecycleMu.Lock()
+	a.closed = true
+	a.lifecycleMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+
+	a.releaseOnce.Do(func() {
+		if a.ownsPool {
+			defer a.dbPool.Close()
+		}
+		if a.ownsReplica {
+			defer a.replica.Close()
+		}
+
+		a.sqlDBMu.Lock()
+		defer a.sqlDBMu.Unlock()
+		if a.sqlDB == nil {
+			return
+		}
+		if err := a.sqlDB.Close(); err != nil {
+			a.releaseErr = fmt.Errorf("failed to close database: %w", err)
+		}
+	})
+
+	return a.releaseErr
+}
+
+// acquire registers an in-flight operation, failing once the adapter is closed.
+// Every successful acquire must be paired with a call to release.
+func (a *PgxAdapter) acquire() error {
+	a.lifecycleMu.RLock()
+	defer a.lifecycleMu.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	a.inflight.Add(1)
+
 	return nil
 }
 
-// GetPool returns the underlying connection pool.
-// Returns nil if the adapter was created with a single connection.
+// release marks an operation registered with acquire as finished
+func (a *PgxAdapter) release() {
+	a.inflight.Done()
+}
+
+// GetConn returns the connection the adapter was created with by NewAdapterWithConn.
+// Returns nil if the adapter was created with a pool or connection config.
+func (a *PgxAdapter) GetConn() *pgx.Conn {
+	return a.conn
+}
+
+// GetPool returns the connection pool passed to NewAdapterWithPool or created by NewAdapter with WithPool.
+// Returns nil otherwise.
 func (a *PgxAdapter) GetPool() *pgxpool.Pool {
 	return a.pool
 }
 
-// GetDB returns the underlying *sql.DB.
+// GetDB returns a *sql.DB sharing the adapter's connection pool, for code written against database/sql.
+// The adapter itself executes through pgx directly. The *sql.DB is opened on first use and closed by Close.
+// Returns nil if the adapter was created with NewAdapterWithConn, or once Close has been called.
 func (a *PgxAdapter) GetDB() *sql.DB {
-	return a.db
+	if a.dbPool == nil {
+		return nil
+	}
+
+	// Holding the read lock keeps Close from starting until the *sql.DB is recorded for it to close
+	a.lifecycleMu.RLock()
+	defer a.lifecycleMu.RUnlock()
+	if a.closed {
+		return nil
+	}
+
+	a.sqlDBMu.Lock()
+	defer a.sqlDBMu.Unlock()
+	if a.sqlDB == nil {
+		a.sqlDB = stdlib.OpenDBFromPool(a.dbPool)
+	}
+
+	return a.sqlDB
 }
 
 // GetTableName returns the table name used by the adapter
@@ -3,11 +3,14 @@ package pgxadapter_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/casbin/casbin/v3/model"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
@@ -211,7 +214,7 @@ func TestNewAdapterWithConfig(t *testing.T) {
 			}
 
 			if adapter.GetPool() != nil {
-				t.Error("pgxadapter.NewAdapterWithConfig() expected pool to be nil (single connection)")
+				t.Error("pgxadapter.NewAdapterWithConfig() expected pool to be nil")
 			}
 		})
 	}
@@ -257,14 +260,11 @@ func TestNewAdapterWithConn(t *testing.T) {
 			_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
 
 			t.Cleanup(func() {
-				cleanConn, err := pgx.Connect(ctx, dbURL)
-				if err == nil {
-					_, _ = cleanConn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
-					cleanConn.Close(ctx)
-				}
+				_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+				conn.Close(ctx)
 			})
 
-			// NewAdapterWithConn closes the passed conn after extracting config
+			// NewAdapterWithConn runs every operation on the passed conn
 			adapter, err := pgxadapter.NewAdapterWithConn(conn, pgxadapter.WithTableName(tt.tableName))
 
 			if tt.wantErr {
@@ -282,13 +282,88 @@ func TestNewAdapterWithConn(t *testing.T) {
 				t.Errorf("pgxadapter.NewAdapterWithConn() tableName = %v, want %v", adapter.GetTableName(), tt.tableName)
 			}
 
-			if adapter.GetDB() == nil {
-				t.Error("pgxadapter.NewAdapterWithConn() expected db to be set")
+			if adapter.GetConn() != conn {
+				t.Error("pgxadapter.NewAdapterWithConn() expected GetConn to return the passed conn")
+			}
+
+			if adapter.GetDB() != nil {
+				t.Error("pgxadapter.NewAdapterWithConn() expected no db to be opened")
+			}
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+				t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+			}
+
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+				t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+			}
+
+			if err := adapter.Close(ctx); err != nil {
+				t.Fatalf("Close() unexpected error: %v", err)
+			}
+
+			if conn.IsClosed() {
+				t.Error("Close() closed the caller's conn")
 			}
 		})
 	}
 }
 
+func TestNewAdapterWithConnConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dbURL := getTestDBURL()
+	tableName := "casbin_test_with_conn_concurrent"
+	quotedTableName := pgx.Identifier{tableName}.Sanitize()
+
+	conn, err := pgx.Connect(ctx, dbURL)
+	if err != nil {
+		t.Skipf("Could not connect to test database: %v", err)
+	}
+	_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+
+	t.Cleanup(func() {
+		_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+		conn.Close(ctx)
+	})
+
+	adapter, err := pgxadapter.NewAdapterWithConn(conn, pgxadapter.WithTableName(tableName))
+	if err != nil {
+		t.Fatalf("pgxadapter.NewAdapterWithConn() unexpected error: %v", err)
+	}
+
+	// A pgx.Conn fails with "conn busy" if used concurrently, so these only pass when serialized
+	const workers = 20
+	errs := make(chan error, workers)
+	for i := range workers {
+		go func() {
+			rule := []string{fmt.Sprintf("user%d", i), "data", "read"}
+			if err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{rule}); err != nil {
+				errs <- err
+				return
+			}
+			_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{rule[0], "data", "write"}}, 0, rule[0])
+			errs <- err
+		}()
+	}
+
+	for range workers {
+		if err := <-errs; err != nil {
+			t.Errorf("concurrent operation unexpected error: %v", err)
+		}
+	}
+
+	page, err := adapter.ListPolicies(ctx, pgxadapter.Filter{V2: []string{"write"}}, pgxadapter.Page{})
+	if err != nil {
+		t.Fatalf("ListPolicies() unexpected error: %v", err)
+	}
+	if page.Total != workers {
+		t.Errorf("ListPolicies() total = %d, want %d", page.Total, workers)
+	}
+}
+
 func TestNewAdapterWithPool(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -362,7 +437,7 @@ func TestNewAdapterWithPool(t *testing.T) {
 }
 
 func TestGetDB(t *testing.T) {
-	t.Run("returns_db_for_conn_adapter", func(t *testing.T) {
+	t.Run("returns_nil_for_conn_adapter", func(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
@@ -390,8 +465,8 @@ func TestGetDB(t *testing.T) {
 			t.Fatalf("Failed to create adapter: %v", err)
 		}
 
-		if adapter.GetDB() == nil {
-			t.Error("pgxadapter.GetDB() expected db to be set for conn adapter")
+		if adapter.GetDB() != nil {
+			t.Error("pgxadapter.GetDB() expected nil for conn adapter")
 		}
 	})
 
@@ -424,6 +499,19 @@ func TestGetDB(t *testing.T) {
 			t.Error("pgxadapter.GetDB() expected db to be set for pool adapter")
 		}
 	})
+
+	t.Run("returns_nil_after_close", func(t *testing.T) {
+		t.Parallel()
+
+		adapter, _ := setupTestAdapter(t, "test_getdb_closed")
+		if err := adapter.Close(context.Background()); err != nil {
+			t.Fatalf("Close() unexpected error: %v", err)
+		}
+
+		if adapter.GetDB() != nil {
+			t.Error("pgxadapter.GetDB() expected nil after Close")
+		}
+	})
 }
 
 func TestWithTableName(t *testing.T) {
@@ -640,15 +728,179 @@ func TestWithIndex(t *testing.T) {
 	}
 }
 
+func TestWithStrict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_with_strict"
+	adapter, db := setupTestAdapter(t, tableName, pgxadapter.WithStrict())
+
+	if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+		t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+	}
+
+	err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+	if !errors.Is(err, pgxadapter.ErrPolicyExists) {
+		t.Errorf("AddPolicyCtx() duplicate error = %v, want ErrPolicyExists", err)
+	}
+
+	err = adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{
+		{"bob", "data2", "write"},
+		{"alice", "data1", "read"},
+	})
+	if !errors.Is(err, pgxadapter.ErrPolicyExists) {
+		t.Errorf("AddPoliciesCtx() duplicate error = %v, want ErrPolicyExists", err)
+	}
+
+	err = adapter.RemovePolicyCtx(ctx, "p", "p", []string{"charlie", "data3", "read"})
+	if !errors.Is(err, pgxadapter.ErrPolicyNotFound) {
+		t.Errorf("RemovePolicyCtx() missing error = %v, want ErrPolicyNotFound", err)
+	}
+
+	err = adapter.RemovePoliciesCtx(ctx, "p", "p", [][]string{
+		{"alice", "data1", "read"},
+		{"charlie", "data3", "read"},
+	})
+	if !errors.Is(err, pgxadapter.ErrPolicyNotFound) {
+		t.Errorf("RemovePoliciesCtx() missing error = %v, want ErrPolicyNotFound", err)
+	}
+
+	// Both failed batches must have been rolled back, leaving only the original rule
+	var count int
+	q, args, _ := testPsql.Select("COUNT(*)").From(tableName).Where(sq.Eq{"v0": "alice"}).ToSql()
+	if err := db.QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		t.Fatalf("Failed to count policies: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("alice policies = %d, want 1", count)
+	}
+
+	q, args, _ = testPsql.Select("COUNT(*)").From(tableName).ToSql()
+	if err := db.QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		t.Fatalf("Failed to count policies: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("total policies = %d, want 1", count)
+	}
+
+	if err := adapter.RemovePolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+		t.Errorf("RemovePolicyCtx() unexpected error: %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Run("closes_owned_pool", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_close_owned_pool"
+		quotedTableName := pgx.Identifier{tableName}.Sanitize()
+
+		adapter, err := pgxadapter.NewAdapter(getTestDBURL(), pgxadapter.WithTableName(tableName), pgxadapter.WithPool())
+		if err != nil {
+			t.Skipf("Could not connect to test database: %v", err)
+		}
+		t.Cleanup(func() {
+			if conn, err := pgx.Connect(ctx, getTestDBURL()); err == nil {
+				_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+				conn.Close(ctx)
+			}
+		})
+
+		if err := adapter.Close(ctx); err != nil {
+			t.Fatalf("Close() unexpected error: %v", err)
+		}
+
+		if err := adapter.GetPool().Ping(ctx); err == nil {
+			t.Error("Close() expected owned pool to be closed")
+		}
+
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); !errors.Is(err, pgxadapter.ErrClosed) {
+			t.Errorf("AddPolicyCtx() after Close() error = %v, want ErrClosed", err)
+		}
+
+		if err := adapter.Close(ctx); err != nil {
+			t.Errorf("second Close() unexpected error: %v", err)
+		}
+	})
+
+	t.Run("leaves_caller_pool_open", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_close_caller_pool")
+
+		if err := adapter.Close(ctx); err != nil {
+			t.Fatalf("Close() unexpected error: %v", err)
+		}
+
+		if err := adapter.GetPool().Ping(ctx); err != nil {
+			t.Errorf("Close() closed the caller's pool: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadPolicyCtx(ctx, m); !errors.Is(err, pgxadapter.ErrClosed) {
+			t.Errorf("LoadPolicyCtx() after Close() error = %v, want ErrClosed", err)
+		}
+	})
+
+	t.Run("waits_for_in_flight_operations", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		tableName := "casbin_test_close_in_flight"
+		adapter, db := setupTestAdapter(t, tableName, pgxadapter.WithWriteLock(pgxadapter.LockAdvisory))
+
+		// Hold the advisory lock so UpdateFilteredPolicies stays in flight
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get connection: %v", err)
+		}
+		defer conn.Close() //nolint:errcheck
+
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtextextended($1, 0))", tableName); err != nil {
+			t.Fatalf("Failed to take advisory lock: %v", err)
+		}
+
+		updateErr := make(chan error, 1)
+		go func() {
+			_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}}, 0, "alice")
+			updateErr <- err
+		}()
+
+		// Give the update time to start and block on the lock
+		time.Sleep(200 * time.Millisecond)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		if err := adapter.Close(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Close() with operation in flight error = %v, want deadline exceeded", err)
+		}
+
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", tableName); err != nil {
+			t.Fatalf("Failed to release advisory lock: %v", err)
+		}
+
+		if err := <-updateErr; err != nil {
+			t.Errorf("UpdateFilteredPoliciesCtx() in flight during Close() unexpected error: %v", err)
+		}
+
+		if err := adapter.Close(ctx); err != nil {
+			t.Errorf("Close() unexpected error: %v", err)
+		}
+	})
+}
+
 // setupTestAdapter creates a test adapter and returns it along with a *sql.DB for verification queries.
+// Additional options are applied after WithTableName.
 // The returned *sql.DB is the adapter's own database connection.
-func setupTestAdapter(t *testing.T, tableName string) (*pgxadapter.PgxAdapter, *sql.DB) {
+func setupTestAdapter(t *testing.T, tableName string, opts ...pgxadapter.Option) (*pgxadapter.PgxAdapter, *sql.DB) {
 	t.Helper()
 
 	ctx := context.Background()
 	dbURL := getTestDBURL()
 
-	// Use a pool-based adapter for tests since NewAdapterWithConn closes the conn
+	// Use a pool-based adapter for tests so verification queries can share its *sql.DB
 	pool, err := pgxpool.New(ctx, dbURL)
 	if err != nil {
 		t.Skipf("Could not create pool for test database: %v", err)
@@ -663,7 +915,8 @@ func setupTestAdapter(t *testing.T, tableName string) (*pgxadapter.PgxAdapter, *
 	quotedTableName := pgx.Identifier{tableName}.Sanitize()
 	_, _ = pool.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
 
-	adapter, err := pgxadapter.NewAdapterWithPool(pool, pgxadapter.WithTableName(tableName))
+	opts = append([]pgxadapter.Option{pgxadapter.WithTableName(tableName)}, opts...)
+	adapter, err := pgxadapter.NewAdapterWithPool(pool, opts...)
 	if err != nil {
 		pool.Close()
 		t.Fatalf("Failed to create adapter: %v", err)
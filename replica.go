@@ -0,0 +1,59 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type forcePrimaryKey struct{}
+
+// WithReadReplica routes LoadPolicy, LoadFilteredPolicy, ListPolicies and IteratePolicies to
+// the given pool, typically connected to a read replica. Mutations, the reads they depend on,
+// and Stats stay on the primary. The pool stays owned by the caller and is not closed by Close.
+func WithReadReplica(pool *pgxpool.Pool) Option {
+	return func(a *PgxAdapter) {
+		a.replica = pool
+		a.replicaConnStr = ""
+	}
+}
+
+// WithReadReplicaConnString is like WithReadReplica but opens a pool from connStr
+// when the adapter is created. The pool is closed by Close.
+func WithReadReplicaConnString(connStr string) Option {
+	return func(a *PgxAdapter) {
+		a.replica = nil
+		a.replicaConnStr = connStr
+	}
+}
+
+// ForcePrimary returns a context that makes reads through it use the primary even when
+// a read replica is configured, for callers that must see their own recent writes.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+// openReplica creates the replica pool requested by WithReadReplicaConnString
+func (a *PgxAdapter) openReplica() error {
+	if a.replicaConnStr == "" {
+		return nil
+	}
+
+	pool, err := pgxpool.New(context.Background(), a.replicaConnStr)
+	if err != nil {
+		return fmt.Errorf("failed to create read replica pool: %w", err)
+	}
+	a.replica = pool
+	a.ownsReplica = true
+
+	return nil
+}
+
+// reader returns where read-only queries made with ctx should run
+func (a *PgxAdapter) reader(ctx context.Context) pgxConn {
+	if forced, _ := ctx.Value(forcePrimaryKey{}).(bool); forced {
+		return a.db
+	}
+	return a.readDB
+}
@@ -0,0 +1,118 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestWithReadReplica(t *testing.T) {
+	tests := []struct {
+		name       string
+		connString bool
+	}{
+		{name: "pool"},
+		{name: "conn_string", connString: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_replica_" + tt.name
+
+			// The replica is simulated by a schema holding its own copy of the table, selected
+			// through search_path so the adapter's unqualified table name resolves to the copy
+			schema := tableName + "_schema"
+			replicaURL := getTestDBURL()
+			if strings.Contains(replicaURL, "?") {
+				replicaURL += "&search_path=" + schema
+			} else {
+				replicaURL += "?search_path=" + schema
+			}
+
+			var opt pgxadapter.Option
+			if tt.connString {
+				opt = pgxadapter.WithReadReplicaConnString(replicaURL)
+			} else {
+				replica, err := pgxpool.New(ctx, replicaURL)
+				if err != nil {
+					t.Skipf("Could not create replica pool: %v", err)
+				}
+				t.Cleanup(replica.Close)
+				opt = pgxadapter.WithReadReplica(replica)
+			}
+
+			adapter, db := setupTestAdapter(t, tableName, opt)
+			t.Cleanup(func() { _ = adapter.Close(ctx) })
+
+			quotedSchema := pgx.Identifier{schema}.Sanitize()
+			quotedReplicaTable := pgx.Identifier{schema, tableName}.Sanitize()
+			t.Cleanup(func() {
+				_, _ = db.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+quotedSchema+" CASCADE")
+			})
+			statements := []string{
+				"DROP SCHEMA IF EXISTS " + quotedSchema + " CASCADE",
+				"CREATE SCHEMA " + quotedSchema,
+				"CREATE TABLE " + quotedReplicaTable + " (LIKE " + pgx.Identifier{tableName}.Sanitize() + " INCLUDING ALL)",
+				"INSERT INTO " + quotedReplicaTable + " (ptype, v0, v1, v2) VALUES ('p', 'replica', 'data1', 'read')",
+			}
+			for _, stmt := range statements {
+				if _, err := db.ExecContext(ctx, stmt); err != nil {
+					t.Fatalf("Failed to create replica table: %v", err)
+				}
+			}
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"primary", "data1", "read"}); err != nil {
+				t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+			}
+
+			loadedUsers := func(ctx context.Context) []string {
+				t.Helper()
+
+				m, _ := model.NewModelFromString(TestModelText)
+				if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+					t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+				}
+
+				var users []string
+				for _, rule := range m["p"]["p"].Policy {
+					users = append(users, rule[0])
+				}
+				return users
+			}
+
+			if users := loadedUsers(ctx); !slices.Equal(users, []string{"replica"}) {
+				t.Errorf("LoadPolicyCtx() loaded %v, want the replica's rules", users)
+			}
+
+			if users := loadedUsers(pgxadapter.ForcePrimary(ctx)); !slices.Equal(users, []string{"primary"}) {
+				t.Errorf("LoadPolicyCtx() with ForcePrimary loaded %v, want the primary's rules", users)
+			}
+
+			page, err := adapter.ListPolicies(ctx, pgxadapter.Filter{}, pgxadapter.Page{})
+			if err != nil {
+				t.Fatalf("ListPolicies() unexpected error: %v", err)
+			}
+			if page.Total != 1 || page.Rules[0].Values[0] != "replica" {
+				t.Errorf("ListPolicies() = %+v, want the replica's rules", page)
+			}
+
+			// Mutations and the reads they depend on stay on the primary
+			oldRules, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"primary", "data2", "read"}}, 0, "primary")
+			if err != nil {
+				t.Fatalf("UpdateFilteredPoliciesCtx() unexpected error: %v", err)
+			}
+			if len(oldRules) != 1 {
+				t.Errorf("UpdateFilteredPoliciesCtx() returned %v, want the primary's rule", oldRules)
+			}
+		})
+	}
+}
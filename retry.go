@@ -0,0 +1,126 @@
+package pgxadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	defaultRetryMaxDelay  = time.Second
+)
+
+// defaultRetryableCodes are the SQLSTATEs retried when RetryPolicy.RetryableCodes is empty:
+// serialization failures, deadlocks, connection exceptions and server shutdown during failover.
+var defaultRetryableCodes = []string{
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+	"08000", // connection_exception
+	"08003", // connection_does_not_exist
+	"08006", // connection_failure
+	"57P01", // admin_shutdown
+	"57P02", // crash_shutdown
+	"57P03", // cannot_connect_now
+}
+
+// RetryPolicy controls how failed operations are re-run.
+// Zero fields fall back to their defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Defaults to 3.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles for each further attempt. Defaults to 50ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts. Defaults to 1s.
+	MaxDelay time.Duration
+	// RetryableCodes is the set of SQLSTATEs to retry. Defaults to serialization failures,
+	// deadlocks, connection exceptions and server shutdowns.
+	RetryableCodes []string
+}
+
+// WithRetry re-runs operations that fail with a retryable error, using exponential backoff with full jitter.
+// Multi-statement operations are retried as a whole transaction. Operations that are not idempotent,
+// such as updates, AddPoliciesWithResults, RemovePoliciesWithResults and every mutation in strict mode,
+// are only retried when the server is known not to have applied them.
+func WithRetry(policy RetryPolicy) Option {
+	return func(a *PgxAdapter) {
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = defaultRetryAttempts
+		}
+		if policy.BaseDelay <= 0 {
+			policy.BaseDelay = defaultRetryBaseDelay
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = defaultRetryMaxDelay
+		}
+		if len(policy.RetryableCodes) == 0 {
+			policy.RetryableCodes = defaultRetryableCodes
+		}
+		a.retryPolicy = &policy
+	}
+}
+
+// retry runs fn until it succeeds, returns a non-retryable error or the policy's attempts are used up.
+// idempotent reports whether fn may safely be re-run after a failure whose outcome on the server is unknown.
+func (a *PgxAdapter) retry(ctx context.Context, idempotent bool, fn func() error) error {
+	if a.retryPolicy == nil {
+		return fn()
+	}
+
+	var err error
+	for attempt := range a.retryPolicy.MaxAttempts {
+		if attempt > 0 {
+			timer := time.NewTimer(a.retryPolicy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = fn()
+		if err == nil || ctx.Err() != nil || !a.retryPolicy.retryable(err, idempotent) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// backoff returns a random delay of up to BaseDelay doubled attempt-1 times, capped at MaxDelay.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 1; shift < 32 && p.BaseDelay<<shift > 0 && p.BaseDelay<<shift < p.MaxDelay {
+		delay = p.BaseDelay << shift
+	}
+	return rand.N(delay) + 1
+}
+
+// retryable reports whether err is worth retrying.
+func (p *RetryPolicy) retryable(err error, idempotent bool) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return slices.Contains(p.RetryableCodes, pgErr.Code)
+	}
+
+	// The request never reached the server
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	// The connection dropped mid-request, so the server may or may not have applied it
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return idempotent
+	}
+
+	return false
+}
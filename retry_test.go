@@ -0,0 +1,146 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// injectSerializationFailures makes the next failures write statements to tableName fail with SQLSTATE 40001.
+func injectSerializationFailures(t *testing.T, db *sql.DB, tableName string, failures int) {
+	t.Helper()
+
+	ctx := context.Background()
+	quotedTableName := pgx.Identifier{tableName}.Sanitize()
+	quotedSeqName := pgx.Identifier{tableName + "_failures"}.Sanitize()
+	quotedFuncName := pgx.Identifier{tableName + "_fail"}.Sanitize()
+
+	statements := []string{
+		"CREATE SEQUENCE " + quotedSeqName,
+		`CREATE FUNCTION ` + quotedFuncName + `() RETURNS trigger AS $$
+		BEGIN
+			IF nextval('` + quotedSeqName + `') <= ` + fmt.Sprint(failures) + ` THEN
+				RAISE EXCEPTION 'injected serialization failure' USING ERRCODE = '40001';
+			END IF;
+			RETURN NULL;
+		END $$ LANGUAGE plpgsql`,
+		"CREATE TRIGGER " + quotedFuncName + " BEFORE INSERT OR UPDATE OR DELETE ON " + quotedTableName +
+			" FOR EACH STATEMENT EXECUTE FUNCTION " + quotedFuncName + "()",
+	}
+
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("Failed to inject serialization failures: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+		_, _ = db.ExecContext(ctx, "DROP FUNCTION IF EXISTS "+quotedFuncName+"()")
+		_, _ = db.ExecContext(ctx, "DROP SEQUENCE IF EXISTS "+quotedSeqName)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		policy    *pgxadapter.RetryPolicy
+		operation func(ctx context.Context, a *pgxadapter.PgxAdapter) error
+		wantErr   bool
+	}{
+		{
+			name:     "add_policy_retried",
+			failures: 2,
+			policy:   &pgxadapter.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				return a.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+		},
+		{
+			name:     "remove_policies_transaction_retried",
+			failures: 1,
+			policy:   &pgxadapter.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				return a.RemovePoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}})
+			},
+		},
+		{
+			name:     "update_filtered_policies_transaction_retried",
+			failures: 2,
+			policy:   &pgxadapter.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				_, err := a.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data2", "read"}}, 0, "alice")
+				return err
+			},
+		},
+		{
+			name:     "attempts_exhausted",
+			failures: 3,
+			policy:   &pgxadapter.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				return a.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			wantErr: true,
+		},
+		{
+			name:     "no_retry_by_default",
+			failures: 1,
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				return a.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			wantErr: true,
+		},
+		{
+			name:     "code_not_in_retryable_set",
+			failures: 1,
+			policy:   &pgxadapter.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, RetryableCodes: []string{"40P01"}},
+			operation: func(ctx context.Context, a *pgxadapter.PgxAdapter) error {
+				return a.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := fmt.Sprintf("casbin_test_retry_%s", tt.name)
+
+			var opts []pgxadapter.Option
+			if tt.policy != nil {
+				opts = append(opts, pgxadapter.WithRetry(*tt.policy))
+			}
+			adapter, db := setupTestAdapter(t, tableName, opts...)
+
+			if err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}); err != nil {
+				t.Fatalf("Failed to setup policies: %v", err)
+			}
+
+			injectSerializationFailures(t, db, tableName, tt.failures)
+
+			err := tt.operation(ctx, adapter)
+
+			if tt.wantErr {
+				var pgErr *pgconn.PgError
+				if !errors.As(err, &pgErr) || pgErr.Code != "40001" {
+					t.Errorf("operation error = %v, want SQLSTATE 40001", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("operation unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package pgxadapter
+
+import "sync"
+
+// statementShape identifies a hot-path operation whose SQL is cached
+type statementShape int
+
+const (
+	shapeAddPolicy statementShape = iota
+	shapeRemovePolicy
+	shapeLoadFiltered
+)
+
+// statementKey identifies the SQL of one operation shape. fields is a bitmask of the fields
+// the statement binds, which fixes both its WHERE clause and its parameter count.
+type statementKey struct {
+	table  string
+	shape  statementShape
+	fields uint64
+}
+
+// maxStatementFields is the most fields a statementKey can describe; statements binding
+// fields beyond it are built for each call.
+const maxStatementFields = 64
+
+// statementCache holds canonical SQL per statement shape. Sending identical SQL text for a shape
+// lets pgx reuse the statement it prepared on each connection, so repeated calls skip parsing
+// and planning. This relies on the connection's default exec mode, QueryExecModeCacheStatement.
+type statementCache struct {
+	mu  sync.RWMutex
+	sql map[statementKey]string
+}
+
+// get returns the SQL cached for key, building and caching it on first use
+func (c *statementCache) get(key statementKey, build func() (string, error)) (string, error) {
+	c.mu.RLock()
+	sqlStr, ok := c.sql[key]
+	c.mu.RUnlock()
+	if ok {
+		return sqlStr, nil
+	}
+
+	sqlStr, err := build()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sql == nil {
+		c.sql = make(map[statementKey]string)
+	}
+	c.sql[key] = sqlStr
+
+	return sqlStr, nil
+}
@@ -0,0 +1,76 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestStatementCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_statement_cache"
+	quotedTableName := pgx.Identifier{tableName}.Sanitize()
+
+	// A single connection makes the session's prepared statements observable
+	conn, err := pgx.Connect(ctx, getTestDBURL())
+	if err != nil {
+		t.Skipf("Could not connect to test database: %v", err)
+	}
+	_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+
+	t.Cleanup(func() {
+		_, _ = conn.Exec(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+		conn.Close(ctx)
+	})
+
+	adapter, err := pgxadapter.NewAdapterWithConn(conn, pgxadapter.WithTableName(tableName))
+	if err != nil {
+		t.Fatalf("pgxadapter.NewAdapterWithConn() unexpected error: %v", err)
+	}
+
+	for i := range 20 {
+		rule := []string{fmt.Sprintf("user%d", i), "data1", "read"}
+		if err := adapter.AddPolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("AddPolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.RemovePolicyCtx(ctx, "p", "p", rule); err != nil {
+			t.Fatalf("RemovePolicyCtx() unexpected error: %v", err)
+		}
+
+		// Filters with a varying number of values share one statement
+		users := make([]string, i+1)
+		for j := range users {
+			users[j] = fmt.Sprintf("user%d", j)
+		}
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{Ptype: []string{"p"}, V0: users}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "add_policy", pattern: "INSERT INTO " + tableName + "%"},
+		{name: "remove_policy", pattern: "DELETE FROM " + tableName + "%"},
+		{name: "load_filtered", pattern: "SELECT % FROM " + tableName + " WHERE %"},
+	}
+
+	for _, tt := range tests {
+		var count int
+		err := conn.QueryRow(ctx, "SELECT COUNT(*) FROM pg_prepared_statements WHERE statement LIKE $1", tt.pattern).Scan(&count)
+		if err != nil {
+			t.Fatalf("Failed to count prepared statements: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("%s prepared %d statements, want 1", tt.name, count)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PolicyStats summarises the rules stored by the adapter.
+type PolicyStats struct {
+	// Total is the number of rules matching the filter.
+	Total int64
+	// ByPtype maps each ptype to its number of matching rules.
+	ByPtype map[string]int64
+	// ByValue holds per-value counts for every column requested in groupBy.
+	ByValue []ValueCount
+	// TableSize is the on-disk size of the tables including indexes and TOAST, in bytes.
+	TableSize int64
+	// LastModified is the commit time of the most recently written rule still stored.
+	// It is zero unless the adapter was created with WithStatsLastModified and track_commit_timestamp
+	// is enabled on the server. Deletes and truncations leave no row behind, so they are not reflected.
+	LastModified time.Time
+	// Indexes reports usage of every index on the tables.
+	Indexes []IndexStats
+}
+
+// WithStatsLastModified makes Stats report PolicyStats.LastModified. Finding it reads every
+// stored rule, so each Stats call costs a sequential scan of the tables.
+func WithStatsLastModified() Option {
+	return func(a *PgxAdapter) {
+		a.statsLastModified = true
+	}
+}
+
+// ValueCount is the number of rules of a ptype sharing a value in a column.
+// Value is empty for rules that have no value in the column.
+type ValueCount struct {
+	Ptype  string
+	Column string
+	Value  string
+	Count  int64
+}
+
+// IndexStats reports the size and usage of a single index.
+type IndexStats struct {
+	Name          string
+	Size          int64
+	Scans         int64
+	TuplesRead    int64
+	TuplesFetched int64
+}
+
+// Stats returns rule counts for the filter grouped by ptype and, for each column in groupBy, by value.
+// Valid groupBy columns are: v0, v1, v2, v3, v4, v5. A column given more than once is counted once.
+func (a *PgxAdapter) Stats(ctx context.Context, filter Filter, groupBy ...string) (_ *PolicyStats, err error) {
+	ctx, op := a.startOperation(ctx, "Stats", "", 0)
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return nil, err
+	}
+	defer a.release()
+
+	// A repeated column would add a duplicate grouping set, whose groups Postgres returns twice
+	var columns []string
+	for _, col := range groupBy {
+		if !slices.Contains(selectColumns[1:], col) {
+			return nil, fmt.Errorf("%w: unknown group by column %q", ErrInvalidFilter, col)
+		}
+		if !slices.Contains(columns, col) {
+			columns = append(columns, col)
+		}
+	}
+	groupBy = columns
+
+	stats := &PolicyStats{ByPtype: make(map[string]int64)}
+
+	if err := a.loadCounts(ctx, stats, filter, groupBy); err != nil {
+		return nil, err
+	}
+	if err := a.loadTableStats(ctx, stats); err != nil {
+		return nil, err
+	}
+	if err := a.loadIndexStats(ctx, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// loadCounts computes per-ptype and per-value counts with a single GROUPING SETS aggregate.
+func (a *PgxAdapter) loadCounts(ctx context.Context, stats *PolicyStats, filter Filter, groupBy []string) error {
+	ptypeColumn := a.columns[0]
+	columns := []string{ptypeColumn}
+	sets := []string{"(" + ptypeColumn + ")"}
+	for _, name := range groupBy {
+		col, err := a.column(name)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+		}
+		columns = append(columns, col, "GROUPING("+col+")")
+		sets = append(sets, "("+ptypeColumn+", "+col+")")
+	}
+	columns = append(columns, "COUNT(*)")
+
+	query := a.applyFilter(a.psql.Select(columns...).From(a.relation(filter.Ptype)), filter).
+		GroupBy("GROUPING SETS (" + strings.Join(sets, ", ") + ")")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := a.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query policy counts: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var ptype string
+		var count int64
+		values := make([]pgtype.Text, len(groupBy))
+		grouping := make([]int, len(groupBy))
+
+		dest := []any{&ptype}
+		for i := range groupBy {
+			dest = append(dest, &values[i], &grouping[i])
+		}
+		dest = append(dest, &count)
+
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		// GROUPING(col) is 0 only for the set that groups by col
+		grouped := slices.Index(grouping, 0)
+		if grouped < 0 {
+			stats.ByPtype[ptype] = count
+			stats.Total += count
+			continue
+		}
+
+		stats.ByValue = append(stats.ByValue, ValueCount{
+			Ptype:  ptype,
+			Column: groupBy[grouped],
+			Value:  values[grouped].String,
+			Count:  count,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// loadTableStats reads the tables' total on-disk size and, with WithStatsLastModified, last commit timestamp.
+func (a *PgxAdapter) loadTableStats(ctx context.Context, stats *PolicyStats) error {
+	quotedTableNames := a.quotedTables()
+
+	// pg_partition_tree lists a partitioned table's partitions, whose sizes make up its own
+	sizeSQL := `SELECT SUM(pg_total_relation_size(p.relid))::bigint, current_setting('track_commit_timestamp') = 'on'
+		FROM unnest($1::regclass[]) AS t, pg_partition_tree(t) AS p`
+
+	var trackCommitTimestamp bool
+	if err := a.db.QueryRow(ctx, sizeSQL, quotedTableNames).Scan(&stats.TableSize, &trackCommitTimestamp); err != nil {
+		return fmt.Errorf("failed to query table size: %w", err)
+	}
+
+	if !a.statsLastModified || !trackCommitTimestamp {
+		return nil
+	}
+
+	for _, quotedTableName := range quotedTableNames {
+		lastModifiedSQL := `SELECT MAX(pg_xact_commit_timestamp(xmin)) FROM ` + quotedTableName
+
+		var lastModified pgtype.Timestamptz
+		if err := a.db.QueryRow(ctx, lastModifiedSQL).Scan(&lastModified); err != nil {
+			return fmt.Errorf("failed to query last modification time: %w", err)
+		}
+		if lastModified.Time.After(stats.LastModified) {
+			stats.LastModified = lastModified.Time
+		}
+	}
+
+	return nil
+}
+
+// loadIndexStats reads size and scan counters for every index on the tables.
+func (a *PgxAdapter) loadIndexStats(ctx context.Context, stats *PolicyStats) error {
+	indexSQL := `SELECT indexrelname, pg_relation_size(indexrelid), idx_scan, idx_tup_read, idx_tup_fetch
+		FROM pg_stat_user_indexes
+		WHERE relid IN (SELECT p.relid FROM unnest($1::regclass[]) AS t, pg_partition_tree(t) AS p)
+		ORDER BY indexrelname`
+
+	rows, err := a.db.Query(ctx, indexSQL, a.quotedTables())
+	if err != nil {
+		return fmt.Errorf("failed to query index stats: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var idx IndexStats
+		if err := rows.Scan(&idx.Name, &idx.Size, &idx.Scans, &idx.TuplesRead, &idx.TuplesFetched); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		stats.Indexes = append(stats.Indexes, idx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
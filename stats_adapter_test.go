@@ -0,0 +1,143 @@
+package pgxadapter_test
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name            string
+		setupPolicies   [][]string
+		filter          pgxadapter.Filter
+		groupBy         []string
+		wantErr         bool
+		expectedTotal   int64
+		expectedByPtype map[string]int64
+		expectedByValue []pgxadapter.ValueCount
+	}{
+		{
+			name: "count_by_ptype",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "bob", "data2", "write"},
+				{"g", "alice", "admin"},
+			},
+			expectedTotal:   3,
+			expectedByPtype: map[string]int64{"p": 2, "g": 1},
+		},
+		{
+			name: "count_by_subject",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "alice", "data2", "write"},
+				{"p", "bob", "data2", "write"},
+			},
+			groupBy:         []string{"v0"},
+			expectedTotal:   3,
+			expectedByPtype: map[string]int64{"p": 3},
+			expectedByValue: []pgxadapter.ValueCount{
+				{Ptype: "p", Column: "v0", Value: "alice", Count: 2},
+				{Ptype: "p", Column: "v0", Value: "bob", Count: 1},
+			},
+		},
+		{
+			name: "count_by_repeated_column",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "alice", "data2", "write"},
+				{"p", "bob", "data2", "write"},
+			},
+			groupBy:         []string{"v0", "v0"},
+			expectedTotal:   3,
+			expectedByPtype: map[string]int64{"p": 3},
+			expectedByValue: []pgxadapter.ValueCount{
+				{Ptype: "p", Column: "v0", Value: "alice", Count: 2},
+				{Ptype: "p", Column: "v0", Value: "bob", Count: 1},
+			},
+		},
+		{
+			name: "count_filtered",
+			setupPolicies: [][]string{
+				{"p", "alice", "data1", "read"},
+				{"p", "bob", "data2", "write"},
+				{"g", "alice", "admin"},
+			},
+			filter:          pgxadapter.Filter{V0: []string{"alice"}},
+			expectedTotal:   2,
+			expectedByPtype: map[string]int64{"p": 1, "g": 1},
+		},
+		{
+			name:    "invalid_group_by_column",
+			groupBy: []string{"ptype; DROP TABLE x"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := fmt.Sprintf("casbin_test_stats_%s", tt.name)
+			adapter, _ := setupTestAdapter(t, tableName)
+
+			for _, policy := range tt.setupPolicies {
+				if err := adapter.AddPolicy(policy[0], policy[0], policy[1:]); err != nil {
+					t.Fatalf("Failed to setup policy: %v", err)
+				}
+			}
+
+			stats, err := adapter.Stats(ctx, tt.filter, tt.groupBy...)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Stats() expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Stats() unexpected error: %v", err)
+			}
+
+			if stats.Total != tt.expectedTotal {
+				t.Errorf("Stats() total = %d, want %d", stats.Total, tt.expectedTotal)
+			}
+
+			if !maps.Equal(stats.ByPtype, tt.expectedByPtype) {
+				t.Errorf("Stats() by ptype = %v, want %v", stats.ByPtype, tt.expectedByPtype)
+			}
+
+			slices.SortFunc(stats.ByValue, func(a, b pgxadapter.ValueCount) int {
+				return cmp.Or(
+					strings.Compare(a.Ptype, b.Ptype),
+					strings.Compare(a.Column, b.Column),
+					strings.Compare(a.Value, b.Value),
+				)
+			})
+			if !slices.Equal(stats.ByValue, tt.expectedByValue) {
+				t.Errorf("Stats() by value = %v, want %v", stats.ByValue, tt.expectedByValue)
+			}
+
+			if stats.TableSize <= 0 {
+				t.Errorf("Stats() table size = %d, want > 0", stats.TableSize)
+			}
+
+			if len(stats.Indexes) == 0 {
+				t.Error("Stats() expected index stats for the unique index")
+			}
+
+			if !stats.LastModified.IsZero() {
+				t.Errorf("Stats() last modified = %v, want zero without WithStatsLastModified", stats.LastModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package pgxadapter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// WithArrayStorage stores each rule as its ptype and a text[] of its values instead of the
+// v0 to v5 columns. Rules may then have any number of fields, and empty fields are stored as
+// empty strings in their position rather than as NULL. The table gets a unique index on
+// (ptype, values) and a GIN index on the values, which filters and removals use.
+// Filters, WithIndex and Stats still name fields v0 to v5, and WithColumnLength only applies to ptype.
+// The array column is named v unless ColumnMapping.Array names another. WithCaseInsensitive is not supported.
+func WithArrayStorage() Option {
+	return func(a *PgxAdapter) {
+		a.arrayStorage = true
+	}
+}
+
+// maxFields returns the most fields a stored rule can have
+func (a *PgxAdapter) maxFields() int {
+	if a.arrayStorage {
+		return math.MaxInt
+	}
+	return valueColumns
+}
+
+// arrayColumn returns the quoted name of the text[] column used by WithArrayStorage
+func (a *PgxAdapter) arrayColumn() string {
+	return a.columns[1]
+}
+
+// fieldColumn returns the expression reading field i of a rule
+func (a *PgxAdapter) fieldColumn(i int) string {
+	if a.arrayStorage {
+		return fmt.Sprintf("%s[%d]", a.arrayColumn(), i+1)
+	}
+	return a.columns[i+1]
+}
+
+// ruleValues returns the values inserted into the rule columns for a rule.
+// In the column layout empty or missing fields are stored as NULL.
+func (a *PgxAdapter) ruleValues(ptype string, rule []string) []any {
+	if a.arrayStorage {
+		return []any{ptype, append([]string{}, rule...)}
+	}
+
+	vals := make([]any, 7)
+	vals[0] = ptype
+
+	for i := range 6 {
+		if i < len(rule) && rule[i] != "" {
+			vals[i+1] = rule[i]
+		} else {
+			vals[i+1] = nil
+		}
+	}
+
+	return vals
+}
+
+// ruleKey returns the stored identity of a rule. In the column layout empty and missing fields are alike.
+func (a *PgxAdapter) ruleKey(rule []string) string {
+	if !a.arrayStorage && len(rule) < valueColumns {
+		rule = append(append([]string{}, rule...), make([]string, valueColumns-len(rule))...)
+	}
+	return strings.Join(rule, "\x00")
+}
+
+// fieldEq returns the condition matching field i of a rule against value
+func (a *PgxAdapter) fieldEq(i int, value string) sq.Sqlizer {
+	if a.arrayStorage {
+		// The containment test lets the GIN index narrow the rows before the positional test
+		return sq.And{
+			sq.Expr(a.arrayColumn()+" @> ARRAY[?::text]", value),
+			sq.Expr(a.fieldColumn(i)+" = ?", value),
+		}
+	}
+	return a.valueEq(a.fieldColumn(i), value)
+}
+
+// fieldEqArgs returns the arguments fieldEq binds for value
+func (a *PgxAdapter) fieldEqArgs(value string) []any {
+	if a.arrayStorage {
+		return []any{value, value}
+	}
+	return []any{value}
+}
+
+// fieldIn returns the condition matching field i of a rule against any of values.
+// Its arguments are those fieldInArgs returns.
+func (a *PgxAdapter) fieldIn(i int, values []string) sq.Sqlizer {
+	if a.arrayStorage {
+		return sq.Expr(a.arrayColumn()+" && ?::text[] AND "+a.fieldColumn(i)+" = ANY(?)", values, values)
+	}
+	return sq.Expr(a.valueIn(a.fieldColumn(i)), values)
+}
+
+// fieldInArgs returns the arguments fieldIn binds for values
+func (a *PgxAdapter) fieldInArgs(values []string) []any {
+	if a.arrayStorage {
+		return []any{values, values}
+	}
+	return []any{values}
+}
+
+// ruleEq returns the condition matching a stored rule exactly
+func (a *PgxAdapter) ruleEq(rule []string) sq.Sqlizer {
+	if a.arrayStorage {
+		return sq.Expr(a.arrayColumn()+" = ?::text[]", append([]string{}, rule...))
+	}
+
+	conds := sq.And{}
+	for i := range 6 {
+		col := a.fieldColumn(i)
+		if i < len(rule) && rule[i] != "" {
+			conds = append(conds, a.valueEq(col, rule[i]))
+		} else {
+			conds = append(conds, sq.Eq{col: nil})
+		}
+	}
+	return conds
+}
+
+// ruleSet returns the assignments storing rule in place of a row's fields
+func (a *PgxAdapter) ruleSet(rule []string) map[string]any {
+	values := a.ruleValues("", rule)[1:]
+
+	setMap := make(map[string]any, len(values))
+	for i, value := range values {
+		setMap[a.columns[i+1]] = value
+	}
+	return setMap
+}
+
+// ruleScanner scans the rule columns of a row
+type ruleScanner struct {
+	arrayStorage bool
+	ptype        string
+	fields       [6]pgtype.Text
+	array        []string
+	dest         []any
+}
+
+// newRuleScanner returns a scanner for rows selecting a.columns, optionally preceded by extra destinations
+func (a *PgxAdapter) newRuleScanner(extra ...any) *ruleScanner {
+	s := &ruleScanner{arrayStorage: a.arrayStorage}
+	s.dest = append(extra, &s.ptype)
+	if a.arrayStorage {
+		s.dest = append(s.dest, &s.array)
+	} else {
+		for i := range s.fields {
+			s.dest = append(s.dest, &s.fields[i])
+		}
+	}
+	return s
+}
+
+// rule returns the fields of the last scanned row. In the column layout NULL fields are skipped.
+func (s *ruleScanner) rule() []string {
+	if s.arrayStorage {
+		return append([]string{}, s.array...)
+	}
+
+	rule := []string{}
+	for _, v := range s.fields {
+		if v.Valid {
+			rule = append(rule, v.String)
+		}
+	}
+	return rule
+}
+
+// key returns the ruleKey of the last scanned row. In the column layout it is built positionally,
+// so a NULL field keeps its place as an empty string.
+func (s *ruleScanner) key() string {
+	if s.arrayStorage {
+		return strings.Join(s.array, "\x00")
+	}
+
+	fields := make([]string, len(s.fields))
+	for i, v := range s.fields {
+		fields[i] = v.String
+	}
+	return strings.Join(fields, "\x00")
+}
+
+// line returns the last scanned row as a policy line starting with its ptype
+func (s *ruleScanner) line() []string {
+	return append([]string{s.ptype}, s.rule()...)
+}
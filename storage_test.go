@@ -0,0 +1,161 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestWithArrayStorage(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error
+		want [][]string
+	}{
+		{
+			name: "duplicate_ignored",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data1", "read"}})
+			},
+			want: [][]string{{"alice", "data1", "read"}, {"bob", "data1", "read"}},
+		},
+		{
+			name: "long_rule",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"bob", "data1", "read", "a", "b", "c", "d", "e"})
+			},
+			want: [][]string{{"alice", "data1", "read"}, {"bob", "data1", "read", "a", "b", "c", "d", "e"}},
+		},
+		{
+			name: "empty_field_kept",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"bob", "", "read"})
+			},
+			want: [][]string{{"alice", "data1", "read"}, {"bob", "", "read"}},
+		},
+		{
+			name: "remove",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemovePolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+		},
+		{
+			name: "remove_filtered",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 1, "data1")
+			},
+		},
+		{
+			name: "remove_filtered_other_position",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "data1")
+			},
+			want: [][]string{{"alice", "data1", "read"}},
+		},
+		{
+			name: "update",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}, []string{"alice", "data1", "write", "tenant1"})
+			},
+			want: [][]string{{"alice", "data1", "write", "tenant1"}},
+		},
+		{
+			name: "update_filtered",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"carol", "data1", "read"}}, 0, "alice")
+				return err
+			},
+			want: [][]string{{"carol", "data1", "read"}},
+		},
+		{
+			name: "save",
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				m, _ := model.NewModelFromString(TestModelText)
+				m.AddPolicy("p", "p", []string{"dave", "data2", "read"})
+				return adapter.SavePolicyCtx(ctx, m)
+			},
+			want: [][]string{{"dave", "data2", "read"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			adapter, _ := setupTestAdapter(t, "casbin_test_array_"+tt.name, pgxadapter.WithArrayStorage())
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			if err := tt.run(ctx, adapter); err != nil {
+				t.Fatalf("run() unexpected error: %v", err)
+			}
+
+			m, _ := model.NewModelFromString(TestModelText)
+			if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+				t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+			}
+			got := m["p"]["p"].Policy
+			slices.SortFunc(got, slices.Compare)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("stored rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter, _ := setupTestAdapter(t, "casbin_test_array_filter", pgxadapter.WithArrayStorage(), pgxadapter.WithIndex("v1"))
+
+		if err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, {"data1", "data2", "read"}}); err != nil {
+			t.Fatalf("Failed to setup policies: %v", err)
+		}
+		if err := adapter.AddPolicyCtx(ctx, "g", "g", []string{"alice", "admin"}); err != nil {
+			t.Fatalf("Failed to setup grouping policy: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{V1: []string{"data1"}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 || !slices.Equal(got[0], []string{"alice", "data1", "read"}) {
+			t.Errorf("loaded p rules = %v, want [[alice data1 read]]", got)
+		}
+
+		page, err := adapter.ListPolicies(ctx, pgxadapter.Filter{Ptype: []string{"g"}}, pgxadapter.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListPolicies() unexpected error: %v", err)
+		}
+		if page.Total != 1 || !slices.Equal(page.Rules[0].Values, []string{"alice", "admin"}) {
+			t.Errorf("ListPolicies() = %+v, want the g rule", page)
+		}
+
+		stats, err := adapter.Stats(ctx, pgxadapter.Filter{}, "v0")
+		if err != nil {
+			t.Fatalf("Stats() unexpected error: %v", err)
+		}
+		if stats.Total != 3 || stats.ByPtype["p"] != 2 {
+			t.Errorf("Stats() = %+v, want 3 rules, 2 of ptype p", stats)
+		}
+	})
+
+	t.Run("case_insensitive_rejected", func(t *testing.T) {
+		t.Parallel()
+
+		bootstrap, _ := setupTestAdapter(t, "casbin_test_array_bootstrap")
+		_, err := pgxadapter.NewAdapterWithPool(bootstrap.GetPool(),
+			pgxadapter.WithTableName("casbin_test_array_ci"),
+			pgxadapter.WithArrayStorage(),
+			pgxadapter.WithCaseInsensitive())
+		if err == nil {
+			t.Error("NewAdapterWithPool() expected an error combining WithArrayStorage and WithCaseInsensitive")
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WithPtypeTable stores the rules of the given ptypes in table instead of the default table.
+// It takes precedence over WithSectionTable. An empty table name is ignored.
+func WithPtypeTable(table string, ptypes ...string) Option {
+	return func(a *PgxAdapter) {
+		if table == "" {
+			return
+		}
+		if a.ptypeTables == nil {
+			a.ptypeTables = make(map[string]string)
+		}
+		for _, ptype := range ptypes {
+			a.ptypeTables[ptype] = table
+		}
+	}
+}
+
+// WithSectionTable stores the rules of every ptype in the given sections, such as "p" or "g",
+// in table instead of the default table. A ptype belongs to the section named by its first
+// letter, as casbin assigns them. An empty table name is ignored.
+func WithSectionTable(table string, sections ...string) Option {
+	return func(a *PgxAdapter) {
+		if table == "" {
+			return
+		}
+		if a.sectionTables == nil {
+			a.sectionTables = make(map[string]string)
+		}
+		for _, sec := range sections {
+			a.sectionTables[sec] = table
+		}
+	}
+}
+
+// tableFor returns the table storing the rules of ptype
+func (a *PgxAdapter) tableFor(ptype string) string {
+	if table, ok := a.ptypeTables[ptype]; ok {
+		return table
+	}
+	if ptype != "" {
+		if table, ok := a.sectionTables[ptype[:1]]; ok {
+			return table
+		}
+	}
+	return a.tableName
+}
+
+// tables returns every table rules are stored in, the default table first and the others by name
+func (a *PgxAdapter) tables() []string {
+	var mapped []string
+	for _, table := range a.ptypeTables {
+		mapped = append(mapped, table)
+	}
+	for _, table := range a.sectionTables {
+		mapped = append(mapped, table)
+	}
+	slices.Sort(mapped)
+
+	tables := []string{a.tableName}
+	for _, table := range slices.Compact(mapped) {
+		if table != a.tableName {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// tablesFor returns the tables that may store rules of ptypes, in tables order, or every table if ptypes is empty
+func (a *PgxAdapter) tablesFor(ptypes []string) []string {
+	tables := a.tables()
+	if len(ptypes) == 0 {
+		return tables
+	}
+
+	var routed []string
+	for _, ptype := range ptypes {
+		routed = append(routed, a.tableFor(ptype))
+	}
+	return slices.DeleteFunc(tables, func(table string) bool {
+		return !slices.Contains(routed, table)
+	})
+}
+
+// relation returns what to select rules of ptypes from: the table storing them, or when they
+// span tables a UNION ALL of those tables, which Postgres filters and orders table by table
+func (a *PgxAdapter) relation(ptypes []string) string {
+	tables := a.tablesFor(ptypes)
+	if len(tables) == 1 {
+		return tables[0]
+	}
+
+	columns := strings.Join(append([]string{a.idColumn}, a.columns...), ", ")
+	selects := make([]string, len(tables))
+	for i, table := range tables {
+		selects[i] = "SELECT " + columns + " FROM " + table
+	}
+	return "(" + strings.Join(selects, " UNION ALL ") + ") AS rules"
+}
+
+// quotedTables returns the quoted names of every table, for use as a regclass[] argument
+func (a *PgxAdapter) quotedTables() []string {
+	var quoted []string
+	for _, table := range a.tables() {
+		quoted = append(quoted, pgx.Identifier{table}.Sanitize())
+	}
+	return quoted
+}
+
+// idDefault returns the id column definition of the tables besides the default one, which draw
+// their ids from the default table's sequence so rule ids stay unique across tables
+func (a *PgxAdapter) idDefault(ctx context.Context) (string, error) {
+	var sequence *string
+	if err := a.db.QueryRow(ctx, "SELECT pg_get_serial_sequence($1, $2)",
+		pgx.Identifier{a.tableName}.Sanitize(), a.columnMapping.ID).Scan(&sequence); err != nil {
+		return "", fmt.Errorf("failed to read id sequence: %w", err)
+	}
+	if sequence == nil {
+		return "", fmt.Errorf("failed to read id sequence: column %s of %s has none", a.columnMapping.ID, a.tableName)
+	}
+
+	return a.idColumn + " INTEGER PRIMARY KEY DEFAULT nextval(" + quoteLiterals([]string{*sequence}) + "::regclass)", nil
+}
@@ -0,0 +1,181 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// setupTablesAdapter returns an adapter storing g rules in their own table and p2 rules in another,
+// dropping all three tables before and after the test
+func setupTablesAdapter(t *testing.T, name string) *pgxadapter.PgxAdapter {
+	t.Helper()
+
+	ctx := context.Background()
+	bootstrap, _ := setupTestAdapter(t, "casbin_test_tables_bootstrap_"+name)
+	pool := bootstrap.GetPool()
+
+	tables := []string{"casbin_test_tables_" + name, "casbin_test_tables_" + name + "_g", "casbin_test_tables_" + name + "_p2"}
+	drop := func() {
+		for _, table := range tables {
+			_, _ = pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+pgx.Identifier{table}.Sanitize()+" CASCADE")
+		}
+	}
+	drop()
+	t.Cleanup(drop)
+
+	adapter, err := pgxadapter.NewAdapterWithPool(pool,
+		pgxadapter.WithTableName(tables[0]),
+		pgxadapter.WithSectionTable(tables[1], "g"),
+		pgxadapter.WithPtypeTable(tables[2], "p2"))
+	if err != nil {
+		t.Fatalf("NewAdapterWithPool() unexpected error: %v", err)
+	}
+
+	if err := adapter.AddPoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}, {"bob", "data2", "write"}}); err != nil {
+		t.Fatalf("Failed to setup p policies: %v", err)
+	}
+	if err := adapter.AddPolicyCtx(ctx, "p", "p2", []string{"carol", "data3", "read"}); err != nil {
+		t.Fatalf("Failed to setup p2 policy: %v", err)
+	}
+	if err := adapter.AddPoliciesCtx(ctx, "g", "g", [][]string{{"alice", "admin"}, {"bob", "admin"}}); err != nil {
+		t.Fatalf("Failed to setup g policies: %v", err)
+	}
+
+	return adapter
+}
+
+func TestWithSectionTable(t *testing.T) {
+	t.Run("routing", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter := setupTablesAdapter(t, "routing")
+		pool := adapter.GetPool()
+
+		counts := map[string]int{}
+		for _, table := range []string{"casbin_test_tables_routing", "casbin_test_tables_routing_g", "casbin_test_tables_routing_p2"} {
+			var count int
+			if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+				t.Fatalf("Failed to count rules in %s: %v", table, err)
+			}
+			counts[table] = count
+		}
+		want := map[string]int{"casbin_test_tables_routing": 2, "casbin_test_tables_routing_g": 2, "casbin_test_tables_routing_p2": 1}
+		for table, count := range want {
+			if counts[table] != count {
+				t.Errorf("%s holds %d rules, want %d", table, counts[table], count)
+			}
+		}
+	})
+
+	t.Run("mutations", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter := setupTablesAdapter(t, "mutations")
+
+		if err := adapter.RemovePolicyCtx(ctx, "g", "g", []string{"bob", "admin"}); err != nil {
+			t.Fatalf("RemovePolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.UpdatePolicyCtx(ctx, "p", "p2", []string{"carol", "data3", "read"}, []string{"carol", "data3", "write"}); err != nil {
+			t.Fatalf("UpdatePolicyCtx() unexpected error: %v", err)
+		}
+		if err := adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 0, "bob"); err != nil {
+			t.Fatalf("RemoveFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if _, err := adapter.UpdateFilteredPoliciesCtx(ctx, "g", "g", [][]string{{"alice", "owner"}}, 1, "admin"); err != nil {
+			t.Fatalf("UpdateFilteredPoliciesCtx() unexpected error: %v", err)
+		}
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+			t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+		}
+		var got [][]string
+		for _, sec := range []string{"p", "g"} {
+			for ptype, ast := range m[sec] {
+				for _, rule := range ast.Policy {
+					got = append(got, append([]string{ptype}, rule...))
+				}
+			}
+		}
+		slices.SortFunc(got, slices.Compare)
+		want := [][]string{{"g", "alice", "owner"}, {"p", "alice", "data1", "read"}, {"p2", "carol", "data3", "write"}}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("loaded rules = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("save", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter := setupTablesAdapter(t, "save")
+
+		m, _ := model.NewModelFromString(TestModelText)
+		m.AddPolicy("p", "p", []string{"dave", "data4", "read"})
+		m.AddPolicy("g", "g", []string{"dave", "admin"})
+		if err := adapter.SavePolicyCtx(ctx, m); err != nil {
+			t.Fatalf("SavePolicyCtx() unexpected error: %v", err)
+		}
+
+		page, err := adapter.ListPolicies(ctx, pgxadapter.Filter{}, pgxadapter.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListPolicies() unexpected error: %v", err)
+		}
+		if page.Total != 2 {
+			t.Errorf("ListPolicies() total = %d, want 2", page.Total)
+		}
+	})
+
+	t.Run("filtered_and_listed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		adapter := setupTablesAdapter(t, "filtered")
+
+		m, _ := model.NewModelFromString(TestModelText)
+		if err := adapter.LoadFilteredPolicyCtx(ctx, m, pgxadapter.Filter{V0: []string{"alice"}}); err != nil {
+			t.Fatalf("LoadFilteredPolicyCtx() unexpected error: %v", err)
+		}
+		if got := m["p"]["p"].Policy; len(got) != 1 {
+			t.Errorf("loaded p rules = %v, want [[alice data1 read]]", got)
+		}
+		if got := m["g"]["g"].Policy; len(got) != 1 {
+			t.Errorf("loaded g rules = %v, want [[alice admin]]", got)
+		}
+
+		// Rule ids are unique across tables, so pages span them without repeating rules
+		var ids []int64
+		page := pgxadapter.Page{Limit: 2}
+		for {
+			result, err := adapter.ListPolicies(ctx, pgxadapter.Filter{}, page)
+			if err != nil {
+				t.Fatalf("ListPolicies() unexpected error: %v", err)
+			}
+			for _, rule := range result.Rules {
+				ids = append(ids, rule.ID)
+			}
+			if result.NextAfterID == 0 {
+				break
+			}
+			page.AfterID = result.NextAfterID
+		}
+		if len(ids) != 5 || !slices.IsSorted(ids) || len(slices.Compact(slices.Clone(ids))) != 5 {
+			t.Errorf("listed ids = %v, want 5 distinct ids in order", ids)
+		}
+
+		stats, err := adapter.Stats(ctx, pgxadapter.Filter{Ptype: []string{"g"}})
+		if err != nil {
+			t.Fatalf("Stats() unexpected error: %v", err)
+		}
+		if stats.Total != 2 || stats.ByPtype["g"] != 2 {
+			t.Errorf("Stats() = %+v, want 2 g rules", stats)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/noho-digital/casbin-pgx-adapter"
+
+// Attribute keys recorded on spans and metrics
+const (
+	attrOperation    = attribute.Key("casbin.operation")
+	attrPtype        = attribute.Key("casbin.ptype")
+	attrRuleCount    = attribute.Key("casbin.rule_count")
+	attrRowsAffected = attribute.Key("casbin.rows_affected")
+	attrTable        = attribute.Key("db.collection.name")
+	attrDBSystem     = attribute.Key("db.system.name")
+	attrError        = attribute.Key("error.type")
+)
+
+// telemetry holds the tracer and instruments created by WithTelemetry
+type telemetry struct {
+	tracer       trace.Tracer
+	duration     metric.Float64Histogram
+	rules        metric.Int64Counter
+	rowsAffected metric.Int64Counter
+}
+
+// WithTelemetry instruments every adapter operation with a span and metrics.
+// Spans carry the table name, ptype, number of rules and rows affected. The meter records
+// the casbin.adapter.operation.duration histogram and the casbin.adapter.rules and
+// casbin.adapter.rows_affected counters. Either provider may be nil to skip that signal.
+// Without this option the adapter records nothing.
+func WithTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) Option {
+	return func(a *PgxAdapter) {
+		a.telemetry = &telemetry{}
+		if tracerProvider != nil {
+			a.telemetry.tracer = tracerProvider.Tracer(instrumentationName)
+		}
+		if meterProvider != nil {
+			meter := meterProvider.Meter(instrumentationName)
+			// Instrument creation only fails for invalid names, which these are not
+			a.telemetry.duration, _ = meter.Float64Histogram("casbin.adapter.operation.duration",
+				metric.WithDescription("Duration of casbin adapter operations."),
+				metric.WithUnit("s"))
+			a.telemetry.rules, _ = meter.Int64Counter("casbin.adapter.rules",
+				metric.WithDescription("Number of rules passed to casbin adapter operations."),
+				metric.WithUnit("{rule}"))
+			a.telemetry.rowsAffected, _ = meter.Int64Counter("casbin.adapter.rows_affected",
+				metric.WithDescription("Number of rows read or written by casbin adapter operations."),
+				metric.WithUnit("{row}"))
+		}
+	}
+}
+
+// operation is an instrumented adapter call. A nil operation records nothing.
+type operation struct {
+	ctx          context.Context
+	t            *telemetry
+	name         string
+	span         trace.Span
+	start        time.Time
+	attrs        []attribute.KeyValue
+	ruleCount    int
+	rowsAffected int64
+}
+
+// startOperation begins instrumenting the named operation over ruleCount rules of ptype.
+// An empty ptype is omitted from the attributes.
+func (a *PgxAdapter) startOperation(ctx context.Context, name string, ptype string, ruleCount int) (context.Context, *operation) {
+	if a.telemetry == nil {
+		return ctx, nil
+	}
+
+	op := &operation{
+		ctx:       ctx,
+		t:         a.telemetry,
+		name:      name,
+		start:     time.Now(),
+		ruleCount: ruleCount,
+		attrs: []attribute.KeyValue{
+			attrOperation.String(name),
+			attrTable.String(a.tableFor(ptype)),
+			attrDBSystem.String("postgresql"),
+		},
+	}
+	if ptype != "" {
+		op.attrs = append(op.attrs, attrPtype.String(ptype))
+	}
+
+	if op.t.tracer != nil {
+		ctx, op.span = op.t.tracer.Start(ctx, "casbin.adapter."+name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(op.attrs...),
+			trace.WithAttributes(attrRuleCount.Int(ruleCount)))
+		op.ctx = ctx
+	}
+
+	return ctx, op
+}
+
+// setRowsAffected records the number of rows the operation read or wrote
+func (op *operation) setRowsAffected(n int64) {
+	if op != nil {
+		op.rowsAffected = n
+	}
+}
+
+// setRuleCount records the number of rules once it is known, for operations that discover it
+func (op *operation) setRuleCount(n int) {
+	if op != nil {
+		op.ruleCount = n
+	}
+}
+
+// end finishes the span and records the operation's metrics
+func (op *operation) end(err error) {
+	if op == nil {
+		return
+	}
+
+	attrs := op.attrs
+	if err != nil {
+		attrs = append(attrs, attrError.String(fmt.Sprintf("%T", err)))
+	}
+	set := metric.WithAttributes(attrs...)
+
+	if op.t.duration != nil {
+		op.t.duration.Record(op.ctx, time.Since(op.start).Seconds(), set)
+		op.t.rules.Add(op.ctx, int64(op.ruleCount), set)
+		op.t.rowsAffected.Add(op.ctx, op.rowsAffected, set)
+	}
+
+	if op.span != nil {
+		op.span.SetAttributes(attrRuleCount.Int(op.ruleCount), attrRowsAffected.Int64(op.rowsAffected))
+		if err != nil {
+			op.span.RecordError(err)
+			op.span.SetStatus(codes.Error, err.Error())
+		}
+		op.span.End()
+	}
+}
@@ -0,0 +1,122 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_telemetry"
+
+	spans := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	adapter, _ := setupTestAdapter(t, tableName, pgxadapter.WithTelemetry(tracerProvider, meterProvider))
+
+	rules := [][]string{
+		{"alice", "data1", "read"},
+		{"bob", "data2", "write"},
+		{"alice", "data1", "read"},
+	}
+	if err := adapter.AddPoliciesCtx(ctx, "p", "p", rules); err != nil {
+		t.Fatalf("AddPoliciesCtx() unexpected error: %v", err)
+	}
+
+	m, _ := model.NewModelFromString(TestModelText)
+	if err := adapter.LoadPolicyCtx(ctx, m); err != nil {
+		t.Fatalf("LoadPolicyCtx() unexpected error: %v", err)
+	}
+
+	if err := adapter.RemoveFilteredPolicyCtx(ctx, "p", "p", 7, "alice"); err == nil {
+		t.Fatal("RemoveFilteredPolicyCtx() expected error for out of range field index")
+	}
+
+	tests := []struct {
+		name         string
+		ruleCount    int64
+		rowsAffected int64
+		ptype        string
+		wantError    bool
+	}{
+		{name: "casbin.adapter.AddPolicies", ruleCount: 3, rowsAffected: 2, ptype: "p"},
+		{name: "casbin.adapter.LoadPolicy", ruleCount: 2, rowsAffected: 2},
+		{name: "casbin.adapter.RemoveFilteredPolicy", ptype: "p", wantError: true},
+	}
+
+	ended := spans.Ended()
+	if len(ended) != len(tests) {
+		t.Fatalf("recorded %d spans, want %d", len(ended), len(tests))
+	}
+
+	for i, tt := range tests {
+		span := ended[i]
+		if span.Name() != tt.name {
+			t.Errorf("span %d name = %q, want %q", i, span.Name(), tt.name)
+			continue
+		}
+
+		attrs := attribute.NewSet(span.Attributes()...)
+		if v, _ := attrs.Value("db.collection.name"); v.AsString() != tableName {
+			t.Errorf("%s db.collection.name = %q, want %q", tt.name, v.AsString(), tableName)
+		}
+		if v, _ := attrs.Value("casbin.ptype"); v.AsString() != tt.ptype {
+			t.Errorf("%s casbin.ptype = %q, want %q", tt.name, v.AsString(), tt.ptype)
+		}
+		if v, _ := attrs.Value("casbin.rule_count"); v.AsInt64() != tt.ruleCount {
+			t.Errorf("%s casbin.rule_count = %d, want %d", tt.name, v.AsInt64(), tt.ruleCount)
+		}
+		if v, _ := attrs.Value("casbin.rows_affected"); v.AsInt64() != tt.rowsAffected {
+			t.Errorf("%s casbin.rows_affected = %d, want %d", tt.name, v.AsInt64(), tt.rowsAffected)
+		}
+		if gotError := span.Status().Code == codes.Error; gotError != tt.wantError {
+			t.Errorf("%s error status = %v, want %v", tt.name, gotError, tt.wantError)
+		}
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	var durations uint64
+	var rowsAffected int64
+	for _, scope := range metrics.ScopeMetrics {
+		for _, metric := range scope.Metrics {
+			switch data := metric.Data.(type) {
+			case metricdata.Histogram[float64]:
+				if metric.Name == "casbin.adapter.operation.duration" {
+					for _, point := range data.DataPoints {
+						durations += point.Count
+					}
+				}
+			case metricdata.Sum[int64]:
+				if metric.Name == "casbin.adapter.rows_affected" {
+					for _, point := range data.DataPoints {
+						rowsAffected += point.Value
+					}
+				}
+			}
+		}
+	}
+
+	if durations != uint64(len(tests)) {
+		t.Errorf("casbin.adapter.operation.duration recorded %d operations, want %d", durations, len(tests))
+	}
+	if rowsAffected != 4 {
+		t.Errorf("casbin.adapter.rows_affected = %d, want 4", rowsAffected)
+	}
+}
@@ -0,0 +1,87 @@
+package pgxadapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OperationClass groups the adapter operations that run inside a transaction
+// so their isolation level can be configured together.
+type OperationClass int
+
+const (
+	// OpSave covers SavePolicy.
+	OpSave OperationClass = iota
+	// OpUpdate covers UpdatePolicies and UpdateFilteredPolicies.
+	OpUpdate
+	// OpRemove covers RemovePolicies.
+	OpRemove
+	// OpAdd covers AddPolicies in strict mode or when the batch is split into chunks.
+	OpAdd
+)
+
+// LockMode selects the lock taken around destructive writes.
+type LockMode int
+
+const (
+	// LockNone takes no extra lock.
+	LockNone LockMode = iota
+	// LockTable locks the table in SHARE ROW EXCLUSIVE mode, blocking every concurrent write
+	// while still allowing reads.
+	LockTable
+	// LockAdvisory takes a transaction-scoped advisory lock keyed on the table name. It only
+	// serialises destructive writes made by adapters using LockAdvisory on the same table.
+	LockAdvisory
+)
+
+// WithIsolation sets the transaction isolation level used for an operation class.
+// Classes without a configured level use the server default, normally READ COMMITTED.
+// Pair stricter levels with WithRetry to re-run transactions that fail with serialization errors.
+func WithIsolation(class OperationClass, level pgx.TxIsoLevel) Option {
+	return func(a *PgxAdapter) {
+		if a.isoLevels == nil {
+			a.isoLevels = make(map[OperationClass]pgx.TxIsoLevel)
+		}
+		a.isoLevels[class] = level
+	}
+}
+
+// WithWriteLock takes the given lock at the start of SavePolicy and UpdateFilteredPolicies
+// so concurrent destructive writes cannot interleave.
+func WithWriteLock(mode LockMode) Option {
+	return func(a *PgxAdapter) {
+		a.writeLock = mode
+	}
+}
+
+// beginTx starts a transaction using the isolation level configured for class
+func (a *PgxAdapter) beginTx(ctx context.Context, class OperationClass) (pgx.Tx, error) {
+	tx, err := beginWithOptions(ctx, a.db, pgx.TxOptions{IsoLevel: a.isoLevels[class]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// lockForWrite takes the configured write lock on tables, in tables order, which is released when tx ends
+func (a *PgxAdapter) lockForWrite(ctx context.Context, tx pgx.Tx, tables ...string) error {
+	for _, table := range tables {
+		var err error
+		switch a.writeLock {
+		case LockTable:
+			quotedTableName := pgx.Identifier{table}.Sanitize()
+			_, err = tx.Exec(ctx, "LOCK TABLE "+quotedTableName+" IN SHARE ROW EXCLUSIVE MODE")
+		case LockAdvisory:
+			_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", table)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to lock table: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+func TestWithIsolation(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []pgxadapter.Option
+		expectedLevel string
+	}{
+		{
+			name:          "default_read_committed",
+			expectedLevel: "read committed",
+		},
+		{
+			name:          "serializable_updates",
+			opts:          []pgxadapter.Option{pgxadapter.WithIsolation(pgxadapter.OpUpdate, pgx.Serializable)},
+			expectedLevel: "serializable",
+		},
+		{
+			name:          "other_class_unaffected",
+			opts:          []pgxadapter.Option{pgxadapter.WithIsolation(pgxadapter.OpSave, pgx.RepeatableRead)},
+			expectedLevel: "read committed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_isolation_" + tt.name
+			adapter, db := setupTestAdapter(t, tableName, tt.opts...)
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			// Record the isolation level of the transaction performing the update
+			quotedTableName := pgx.Identifier{tableName}.Sanitize()
+			quotedLogName := pgx.Identifier{tableName + "_log"}.Sanitize()
+			quotedFuncName := pgx.Identifier{tableName + "_record"}.Sanitize()
+			statements := []string{
+				"CREATE TABLE " + quotedLogName + " (level TEXT)",
+				`CREATE FUNCTION ` + quotedFuncName + `() RETURNS trigger AS $$
+				BEGIN
+					INSERT INTO ` + quotedLogName + ` VALUES (current_setting('transaction_isolation'));
+					RETURN NULL;
+				END $$ LANGUAGE plpgsql`,
+				"CREATE TRIGGER " + quotedFuncName + " AFTER UPDATE ON " + quotedTableName +
+					" FOR EACH STATEMENT EXECUTE FUNCTION " + quotedFuncName + "()",
+			}
+			for _, stmt := range statements {
+				if _, err := db.ExecContext(ctx, stmt); err != nil {
+					t.Fatalf("Failed to create isolation recorder: %v", err)
+				}
+			}
+			t.Cleanup(func() {
+				_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quotedTableName+" CASCADE")
+				_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quotedLogName)
+				_, _ = db.ExecContext(ctx, "DROP FUNCTION IF EXISTS "+quotedFuncName+"()")
+			})
+
+			err := adapter.UpdatePoliciesCtx(ctx, "p", "p", [][]string{{"alice", "data1", "read"}}, [][]string{{"alice", "data1", "write"}})
+			if err != nil {
+				t.Fatalf("UpdatePoliciesCtx() unexpected error: %v", err)
+			}
+
+			var level string
+			if err := db.QueryRowContext(ctx, "SELECT level FROM "+quotedLogName).Scan(&level); err != nil {
+				t.Fatalf("Failed to read recorded isolation level: %v", err)
+			}
+
+			if level != tt.expectedLevel {
+				t.Errorf("UpdatePoliciesCtx() isolation = %q, want %q", level, tt.expectedLevel)
+			}
+		})
+	}
+}
+
+func TestWithWriteLock(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        pgxadapter.LockMode
+		wantBlocked bool
+	}{
+		{
+			name:        "advisory_lock_blocks",
+			mode:        pgxadapter.LockAdvisory,
+			wantBlocked: true,
+		},
+		{
+			name:        "no_lock_does_not_block",
+			mode:        pgxadapter.LockNone,
+			wantBlocked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_write_lock_" + tt.name
+			adapter, db := setupTestAdapter(t, tableName, pgxadapter.WithWriteLock(tt.mode))
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			// Simulate another admin holding the advisory lock
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatalf("Failed to get connection: %v", err)
+			}
+			defer conn.Close() //nolint:errcheck
+
+			if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtextextended($1, 0))", tableName); err != nil {
+				t.Fatalf("Failed to take advisory lock: %v", err)
+			}
+			defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtextextended($1, 0))", tableName) //nolint:errcheck
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			defer cancel()
+
+			_, err = adapter.UpdateFilteredPoliciesCtx(timeoutCtx, "p", "p", [][]string{{"alice", "data2", "read"}}, 0, "alice")
+
+			if tt.wantBlocked {
+				if err == nil {
+					t.Error("UpdateFilteredPoliciesCtx() expected to block on the advisory lock until the deadline")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("UpdateFilteredPoliciesCtx() unexpected error: %v", err)
+			}
+		})
+	}
+}
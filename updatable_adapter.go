@@ -2,10 +2,11 @@ package pgxadapter
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // UpdatePolicy updates a policy rule from storage
@@ -24,56 +25,88 @@ func (a *PgxAdapter) UpdateFilteredPolicies(sec string, ptype string, newRules [
 }
 
 // UpdatePolicyCtx updates a policy rule from storage
-func (a *PgxAdapter) UpdatePolicyCtx(ctx context.Context, sec string, ptype string, oldRule, newRule []string) error {
-	// Build WHERE clause for old rule
-	updateBuilder := a.psql.Update(a.tableName).Where(sq.Eq{"ptype": ptype})
-
-	// Add conditions for each old rule value
-	for i := range 6 {
-		col := colParams[i]
-		if i < len(oldRule) && oldRule[i] != "" {
-			updateBuilder = updateBuilder.Where(sq.Eq{col: oldRule[i]})
-		} else {
-			updateBuilder = updateBuilder.Where(sq.Eq{col: nil})
-		}
-	}
+func (a *PgxAdapter) UpdatePolicyCtx(ctx context.Context, sec string, ptype string, oldRule, newRule []string) (err error) {
+	ctx, op := a.startOperation(ctx, "UpdatePolicy", ptype, 1)
+	defer func() { op.end(err) }()
 
-	// Build SET clause for new rule
-	setMap := make(map[string]any)
-	for i := range 6 {
-		col := colParams[i]
-		if i < len(newRule) && newRule[i] != "" {
-			setMap[col] = newRule[i]
-		} else {
-			setMap[col] = nil
-		}
+	if err := a.acquire(); err != nil {
+		return err
 	}
-	updateBuilder = updateBuilder.SetMap(setMap)
+	defer a.release()
 
-	sqlQuery, args, err := updateBuilder.ToSql()
+	m := &Mutation{Kind: MutationUpdatePolicies, Sec: sec, Ptype: ptype, Rules: [][]string{oldRule}, NewRules: [][]string{newRule}}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
 	if err != nil {
-		return fmt.Errorf("failed to build update query: %w", err)
+		return err
+	}
+	ptype = m.Ptype
+	if oldRule, newRule, err = m.singleRules(); err != nil {
+		return err
+	}
+	oldRule, newRule = a.normalizeRule(ptype, oldRule), a.normalizeRule(ptype, newRule)
+
+	if err := a.checkRuleLength(oldRule); err != nil {
+		return newPolicyError(err, ptype, oldRule)
+	}
+	if err := a.checkRule(ptype, newRule); err != nil {
+		return err
 	}
 
-	result, err := a.db.ExecContext(ctx, sqlQuery, args...)
+	if err := a.retry(ctx, false, func() error {
+		return a.updatePolicy(ctx, ptype, oldRule, newRule)
+	}); err != nil {
+		return err
+	}
+	op.setRowsAffected(1)
+
+	return nil
+}
+
+// updatePolicy replaces the row exactly matching oldRule with newRule
+func (a *PgxAdapter) updatePolicy(ctx context.Context, ptype string, oldRule, newRule []string) error {
+	// Build WHERE clause for old rule
+	updateBuilder := a.psql.Update(a.tableFor(ptype)).Where(sq.Eq{a.columns[0]: ptype})
+
+	// Match the old rule exactly and store the new rule in its place
+	updateBuilder = updateBuilder.Where(a.ruleEq(oldRule)).SetMap(a.ruleSet(newRule))
+
+	sqlQuery, args, err := updateBuilder.ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to update policy: %w", err)
+		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	tag, err := a.db.Exec(ctx, sqlQuery, args...)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return newPolicyError(fmt.Errorf("failed to update policy: %w", err), ptype, oldRule)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("policy not found")
+	if tag.RowsAffected() == 0 {
+		return newPolicyError(ErrPolicyNotFound, ptype, oldRule)
 	}
 
 	return nil
 }
 
 // UpdatePoliciesCtx updates multiple policy rules in storage within a transaction
-func (a *PgxAdapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype string, oldRules, newRules [][]string) error {
+func (a *PgxAdapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype string, oldRules, newRules [][]string) (err error) {
+	ctx, op := a.startOperation(ctx, "UpdatePolicies", ptype, len(oldRules))
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return err
+	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationUpdatePolicies, Sec: sec, Ptype: ptype, Rules: oldRules, NewRules: newRules}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return err
+	}
+	ptype, oldRules, newRules = m.Ptype, m.Rules, m.NewRules
+	oldRules, newRules = a.normalizeRules(ptype, oldRules), a.normalizeRules(ptype, newRules)
+
 	if len(oldRules) != len(newRules) {
 		return fmt.Errorf("old rules and new rules must have the same length")
 	}
@@ -82,62 +115,67 @@ func (a *PgxAdapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype st
 		return nil
 	}
 
-	tx, err := a.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	for i, rule := range oldRules {
+		if err := a.checkRuleLength(rule); err != nil {
+			return newBatchPolicyError(err, ptype, rule, i)
+		}
+	}
+	if err := a.checkRules(ptype, newRules); err != nil {
+		return err
 	}
-	defer tx.Rollback()
 
+	if err := a.retry(ctx, false, func() error {
+		return a.updatePolicies(ctx, ptype, oldRules, newRules)
+	}); err != nil {
+		return err
+	}
+	op.setRowsAffected(int64(len(oldRules)))
+
+	return nil
+}
+
+// updatePolicies replaces each old rule with its new rule inside a single transaction,
+// pipelining one statement per rule so a missing old rule is still reported by its index
+func (a *PgxAdapter) updatePolicies(ctx context.Context, ptype string, oldRules, newRules [][]string) error {
+	batch := &pgx.Batch{}
 	for i := range oldRules {
 		oldRule := oldRules[i]
 		newRule := newRules[i]
 
 		// Build WHERE clause for old rule
-		updateBuilder := a.psql.Update(a.tableName).Where(sq.Eq{"ptype": ptype})
-
-		// Add conditions for each old rule value
-		for j := range 6 {
-			col := colParams[j]
-			if j < len(oldRule) && oldRule[j] != "" {
-				updateBuilder = updateBuilder.Where(sq.Eq{col: oldRule[j]})
-			} else {
-				updateBuilder = updateBuilder.Where(sq.Eq{col: nil})
-			}
-		}
+		updateBuilder := a.psql.Update(a.tableFor(ptype)).Where(sq.Eq{a.columns[0]: ptype})
 
-		// Build SET clause for new rule
-		setMap := make(map[string]any)
-		for j := range 6 {
-			col := colParams[j]
-			if j < len(newRule) && newRule[j] != "" {
-				setMap[col] = newRule[j]
-			} else {
-				setMap[col] = nil
-			}
-		}
-		updateBuilder = updateBuilder.SetMap(setMap)
+		// Match the old rule exactly and store the new rule in its place
+		updateBuilder = updateBuilder.Where(a.ruleEq(oldRule)).SetMap(a.ruleSet(newRule))
 
 		sqlQuery, args, err := updateBuilder.ToSql()
 		if err != nil {
 			return fmt.Errorf("failed to build update query: %w", err)
 		}
 
-		result, err := tx.ExecContext(ctx, sqlQuery, args...)
-		if err != nil {
-			return fmt.Errorf("failed to update policy: %w", err)
-		}
+		batch.Queue(sqlQuery, args...)
+	}
 
-		rowsAffected, err := result.RowsAffected()
+	tx, err := a.beginTx(ctx, OpUpdate)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	err = execBatch(ctx, tx, batch, func(i int, tag pgconn.CommandTag, err error) error {
 		if err != nil {
-			return fmt.Errorf("failed to get rows affected: %w", err)
+			return newBatchPolicyError(fmt.Errorf("failed to update policy: %w", err), ptype, oldRules[i], i)
 		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("policy not found at index %d", i)
+		if tag.RowsAffected() == 0 {
+			return newBatchPolicyError(ErrPolicyNotFound, ptype, oldRules[i], i)
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
@@ -145,27 +183,70 @@ func (a *PgxAdapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype st
 }
 
 // UpdateFilteredPoliciesCtx deletes old rules matching the filter and adds new rules
-func (a *PgxAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
-	if fieldIndex < 0 || fieldIndex > 5 {
-		return nil, fmt.Errorf("invalid field index: %d", fieldIndex)
+func (a *PgxAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) (oldPolicies [][]string, err error) {
+	ctx, op := a.startOperation(ctx, "UpdateFilteredPolicies", ptype, len(newRules))
+	defer func() { op.end(err) }()
+
+	if err := a.acquire(); err != nil {
+		return nil, err
 	}
+	defer a.release()
+
+	m := &Mutation{Kind: MutationUpdateFilteredPolicies, Sec: sec, Ptype: ptype, NewRules: newRules, FieldIndex: fieldIndex, FieldValues: fieldValues}
+	after, err := a.intercept(ctx, m)
+	defer func() { err = after(err) }()
+	if err != nil {
+		return nil, err
+	}
+	ptype, newRules, fieldIndex, fieldValues = m.Ptype, m.NewRules, m.FieldIndex, m.FieldValues
+	newRules = a.normalizeRules(ptype, newRules)
+	fieldValues = a.normalizeFieldValues(ptype, fieldIndex, fieldValues)
+
+	if err := a.checkFieldIndex(ptype, fieldIndex, fieldValues); err != nil {
+		return nil, err
+	}
+
+	if err := a.checkRules(ptype, newRules); err != nil {
+		return nil, err
+	}
+
+	err = a.retry(ctx, false, func() error {
+		var err error
+		oldPolicies, err = a.updateFilteredPolicies(ctx, ptype, newRules, fieldIndex, fieldValues)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Rows deleted plus rows inserted
+	op.setRowsAffected(int64(len(oldPolicies) + len(newRules)))
+
+	return oldPolicies, nil
+}
 
-	tx, err := a.db.BeginTx(ctx, nil)
+// updateFilteredPolicies deletes the rules matching the filter and inserts newRules in a single transaction,
+// returning the deleted rules
+func (a *PgxAdapter) updateFilteredPolicies(ctx context.Context, ptype string, newRules [][]string, fieldIndex int, fieldValues []string) ([][]string, error) {
+	tx, err := a.beginTx(ctx, OpUpdate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	table := a.tableFor(ptype)
+	if err := a.lockForWrite(ctx, tx, table); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
 	// Build query to find matching old policies
-	selectBuilder := a.psql.Select(selectColumns...).From(a.tableName).Where(sq.Eq{"ptype": ptype})
+	selectBuilder := a.psql.Select(a.columns...).From(table).Where(sq.Eq{a.columns[0]: ptype})
 
 	// Add filter conditions
 	for i := range fieldValues {
-		if i+fieldIndex > 5 {
+		if i+fieldIndex >= a.maxFields() {
 			break
 		}
-		col := colParams[i+fieldIndex]
-		selectBuilder = selectBuilder.Where(sq.Eq{col: fieldValues[i]})
+		selectBuilder = selectBuilder.Where(a.fieldEq(i+fieldIndex, fieldValues[i]))
 	}
 
 	sqlQuery, args, err := selectBuilder.ToSql()
@@ -173,42 +254,20 @@ func (a *PgxAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string,
 		return nil, fmt.Errorf("failed to build select query: %w", err)
 	}
 
-	rows, err := tx.QueryContext(ctx, sqlQuery, args...)
+	rows, err := tx.Query(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query policies: %w", err)
 	}
 
 	var oldPolicies [][]string
+	scanner := a.newRuleScanner()
 	for rows.Next() {
-		var ptypeVal string
-		var v0, v1, v2, v3, v4, v5 sql.NullString
-
-		if err := rows.Scan(&ptypeVal, &v0, &v1, &v2, &v3, &v4, &v5); err != nil {
+		if err := rows.Scan(scanner.dest...); err != nil {
 			rows.Close()
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		policy := []string{}
-		if v0.Valid {
-			policy = append(policy, v0.String)
-		}
-		if v1.Valid {
-			policy = append(policy, v1.String)
-		}
-		if v2.Valid {
-			policy = append(policy, v2.String)
-		}
-		if v3.Valid {
-			policy = append(policy, v3.String)
-		}
-		if v4.Valid {
-			policy = append(policy, v4.String)
-		}
-		if v5.Valid {
-			policy = append(policy, v5.String)
-		}
-
-		oldPolicies = append(oldPolicies, policy)
+		oldPolicies = append(oldPolicies, scanner.rule())
 	}
 	rows.Close()
 
@@ -217,13 +276,12 @@ func (a *PgxAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string,
 	}
 
 	// Delete old policies matching the filter
-	deleteBuilder := a.psql.Delete(a.tableName).Where(sq.Eq{"ptype": ptype})
+	deleteBuilder := a.psql.Delete(table).Where(sq.Eq{a.columns[0]: ptype})
 	for i := range fieldValues {
-		if i+fieldIndex > 5 {
+		if i+fieldIndex >= a.maxFields() {
 			break
 		}
-		col := colParams[i+fieldIndex]
-		deleteBuilder = deleteBuilder.Where(sq.Eq{col: fieldValues[i]})
+		deleteBuilder = deleteBuilder.Where(a.fieldEq(i+fieldIndex, fieldValues[i]))
 	}
 
 	sqlQuery, args, err = deleteBuilder.ToSql()
@@ -231,40 +289,22 @@ func (a *PgxAdapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string,
 		return nil, fmt.Errorf("failed to build delete query: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx, sqlQuery, args...)
+	_, err = tx.Exec(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete policies: %w", err)
 	}
 
 	// Insert new policies
-	if len(newRules) > 0 {
-		insertBuilder := a.psql.Insert(a.tableName).Columns(insertColumns...)
-
-		for _, rule := range newRules {
-			vals := make([]any, 7)
-			vals[0] = ptype
-			for i := range 6 {
-				if i < len(rule) && rule[i] != "" {
-					vals[i+1] = rule[i]
-				} else {
-					vals[i+1] = nil
-				}
-			}
-			insertBuilder = insertBuilder.Values(vals...)
-		}
-
-		sqlQuery, args, err = insertBuilder.ToSql()
-		if err != nil {
-			return nil, fmt.Errorf("failed to build insert query: %w", err)
-		}
+	values := make([][]any, len(newRules))
+	for i, rule := range newRules {
+		values[i] = a.ruleValues(ptype, rule)
+	}
 
-		_, err = tx.ExecContext(ctx, sqlQuery, args...)
-		if err != nil {
-			return nil, fmt.Errorf("failed to insert new policies: %w", err)
-		}
+	if err := a.insertPolicies(ctx, tx, table, values); err != nil {
+		return nil, fmt.Errorf("failed to insert new policies: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
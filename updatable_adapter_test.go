@@ -2,11 +2,13 @@ package pgxadapter_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
 )
 
 func TestUpdatePolicy(t *testing.T) {
@@ -220,6 +222,56 @@ func TestUpdatePolicies(t *testing.T) {
 	}
 }
 
+func TestUpdatePoliciesLargeBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tableName := "casbin_test_update_large_batch"
+	adapter, db := setupTestAdapter(t, tableName)
+
+	oldRules := make([][]string, 5000)
+	newRules := make([][]string, len(oldRules))
+	for i := range oldRules {
+		oldRules[i] = []string{fmt.Sprintf("user%d", i), "data1", "read"}
+		newRules[i] = []string{fmt.Sprintf("user%d", i), "data1", "write"}
+	}
+	if err := adapter.AddPoliciesCtx(ctx, "p", "p", oldRules); err != nil {
+		t.Fatalf("Failed to setup policies: %v", err)
+	}
+
+	// A missing rule deep in the batch is reported by its index and rolls back the rest
+	missing := slices.Clone(oldRules)
+	missing[3210] = []string{"nobody", "data1", "read"}
+
+	err := adapter.UpdatePoliciesCtx(ctx, "p", "p", missing, newRules)
+	var policyErr *pgxadapter.PolicyError
+	if !errors.As(err, &policyErr) || !errors.Is(err, pgxadapter.ErrPolicyNotFound) {
+		t.Fatalf("UpdatePoliciesCtx() error = %v, want PolicyError wrapping ErrPolicyNotFound", err)
+	}
+	if policyErr.Index != 3210 {
+		t.Errorf("UpdatePoliciesCtx() error index = %d, want 3210", policyErr.Index)
+	}
+
+	var written int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName+" WHERE v2 = 'write'").Scan(&written); err != nil {
+		t.Fatalf("Failed to count updated policies: %v", err)
+	}
+	if written != 0 {
+		t.Errorf("UpdatePoliciesCtx() should have rolled back, but %d rules were updated", written)
+	}
+
+	if err := adapter.UpdatePoliciesCtx(ctx, "p", "p", oldRules, newRules); err != nil {
+		t.Fatalf("UpdatePoliciesCtx() unexpected error: %v", err)
+	}
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableName+" WHERE v2 = 'write'").Scan(&written); err != nil {
+		t.Fatalf("Failed to count updated policies: %v", err)
+	}
+	if written != len(newRules) {
+		t.Errorf("UpdatePoliciesCtx() updated %d rules, want %d", written, len(newRules))
+	}
+}
+
 func TestUpdateFilteredPolicies(t *testing.T) {
 	tests := []struct {
 		name            string
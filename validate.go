@@ -0,0 +1,121 @@
+package pgxadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v3/model"
+)
+
+// ruleShape is the field layout a model defines for one ptype
+type ruleShape struct {
+	tokens []string
+	// params is the number of optional trailing fields, the parameters of a conditional role definition
+	params int
+}
+
+// WithModel makes AddPolicy, AddPolicies, UpdatePolicy, UpdatePolicies, UpdateFilteredPolicies and
+// SavePolicy check every rule against the model's policy and role definitions.
+// Rules whose ptype the model does not define fail with ErrUnknownPtype, and rules with
+// the wrong number of fields fail with ErrRuleArity.
+// Only the definitions are read, when the adapter is created; later changes to m are not seen.
+func WithModel(m model.Model) Option {
+	return func(a *PgxAdapter) {
+		a.model = m
+		a.modelText = ""
+	}
+}
+
+// WithModelText is like WithModel but parses the model from text when the adapter is created.
+func WithModelText(text string) Option {
+	return func(a *PgxAdapter) {
+		a.model = nil
+		a.modelText = text
+	}
+}
+
+// loadModel records the rule shapes of the model given by WithModel or WithModelText
+func (a *PgxAdapter) loadModel() error {
+	m := a.model
+	if a.modelText != "" {
+		var err error
+		if m, err = model.NewModelFromString(a.modelText); err != nil {
+			return fmt.Errorf("failed to parse model: %w", err)
+		}
+	}
+	if m == nil {
+		return nil
+	}
+
+	a.shapes = make(map[string]ruleShape)
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			tokens := make([]string, len(ast.Tokens))
+			for i, token := range ast.Tokens {
+				tokens[i] = strings.TrimSpace(token)
+			}
+			a.shapes[ptype] = ruleShape{tokens: tokens, params: len(ast.ParamsTokens)}
+		}
+	}
+
+	return nil
+}
+
+// checkRuleShape returns ErrUnknownPtype or ErrRuleArity if rule does not fit the model
+// given by WithModel. Without a model every rule is accepted.
+func (a *PgxAdapter) checkRuleShape(ptype string, rule []string) error {
+	if a.shapes == nil {
+		return nil
+	}
+
+	shape, ok := a.shapes[ptype]
+	if !ok {
+		return fmt.Errorf("%w: the model does not define %q", ErrUnknownPtype, ptype)
+	}
+
+	if n := len(shape.tokens); len(rule) < n || len(rule) > n+shape.params {
+		want := fmt.Sprint(n)
+		if shape.params > 0 {
+			want = fmt.Sprintf("%d to %d", n, n+shape.params)
+		}
+		return fmt.Errorf("%w: got %d fields, want %s (%s)", ErrRuleArity, len(rule), want, strings.Join(shape.tokens, ", "))
+	}
+
+	return nil
+}
+
+// checkRule runs the rule length, value length, model and field value checks on a single-rule operation's rule
+func (a *PgxAdapter) checkRule(ptype string, rule []string) error {
+	if err := a.checkRuleLength(rule); err != nil {
+		return newPolicyError(err, ptype, rule)
+	}
+	if err := a.checkValueLengths(ptype, rule); err != nil {
+		return newPolicyError(err, ptype, rule)
+	}
+	if err := a.checkRuleShape(ptype, rule); err != nil {
+		return newPolicyError(err, ptype, rule)
+	}
+	if err := a.checkRuleValues(ptype, rule); err != nil {
+		return newPolicyError(err, ptype, rule)
+	}
+	return nil
+}
+
+// checkRules runs the rule length, value length, model and field value checks on a batch operation's rules
+func (a *PgxAdapter) checkRules(ptype string, rules [][]string) error {
+	for i, rule := range rules {
+		if err := a.checkRuleLength(rule); err != nil {
+			return newBatchPolicyError(err, ptype, rule, i)
+		}
+		if err := a.checkValueLengths(ptype, rule); err != nil {
+			return newBatchPolicyError(err, ptype, rule, i)
+		}
+		if err := a.checkRuleShape(ptype, rule); err != nil {
+			return newBatchPolicyError(err, ptype, rule, i)
+		}
+		if err := a.checkRuleValues(ptype, rule); err != nil {
+			return newBatchPolicyError(err, ptype, rule, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package pgxadapter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/casbin/casbin/v3/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgxadapter "github.com/noho-digital/casbin-pgx-adapter"
+)
+
+// conditionalModelText defines a role definition with optional condition parameters
+var conditionalModelText = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _, (_, _)
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+
+func TestWithModel(t *testing.T) {
+	m, err := model.NewModelFromString(TestModelText)
+	if err != nil {
+		t.Fatalf("Failed to parse model: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opt     pgxadapter.Option
+		run     func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error
+		wantErr error
+	}{
+		{
+			name: "valid_policy",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1", "read"})
+			},
+		},
+		{
+			name: "valid_grouping",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "g", "g", []string{"alice", "admin"})
+			},
+		},
+		{
+			name: "policy_too_short",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p", []string{"alice", "data1"})
+			},
+			wantErr: pgxadapter.ErrRuleArity,
+		},
+		{
+			name: "grouping_too_long",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPoliciesCtx(ctx, "g", "g", [][]string{{"alice", "admin"}, {"bob", "admin", "domain1"}})
+			},
+			wantErr: pgxadapter.ErrRuleArity,
+		},
+		{
+			name: "unknown_ptype",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPolicyCtx(ctx, "p", "p2", []string{"alice", "data1", "read"})
+			},
+			wantErr: pgxadapter.ErrUnknownPtype,
+		},
+		{
+			name: "update_to_invalid_rule",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.UpdatePolicyCtx(ctx, "p", "p", []string{"bob", "data2", "write"}, []string{"bob", "data2"})
+			},
+			wantErr: pgxadapter.ErrRuleArity,
+		},
+		{
+			name: "update_filtered_to_invalid_rule",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				_, err := adapter.UpdateFilteredPoliciesCtx(ctx, "p", "p", [][]string{{"bob"}}, 0, "bob")
+				return err
+			},
+			wantErr: pgxadapter.ErrRuleArity,
+		},
+		{
+			name: "save_unknown_ptype",
+			opt:  pgxadapter.WithModel(m),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				saved, _ := model.NewModelFromString(TestModelText)
+				saved.AddDef("p", "p2", "sub, act")
+				saved.AddPolicy("p", "p2", []string{"alice", "read"}) //nolint:errcheck
+				return adapter.SavePolicyCtx(ctx, saved)
+			},
+			wantErr: pgxadapter.ErrUnknownPtype,
+		},
+		{
+			name: "model_text_with_condition_params",
+			opt:  pgxadapter.WithModelText(conditionalModelText),
+			run: func(ctx context.Context, adapter *pgxadapter.PgxAdapter) error {
+				return adapter.AddPoliciesCtx(ctx, "g", "g", [][]string{
+					{"alice", "admin"},
+					{"bob", "admin", "2026-01-01", "2027-01-01"},
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			tableName := "casbin_test_model_" + tt.name
+			adapter, db := setupTestAdapter(t, tableName, tt.opt)
+
+			if err := adapter.AddPolicyCtx(ctx, "p", "p", []string{"bob", "data2", "write"}); err != nil {
+				t.Fatalf("Failed to setup policy: %v", err)
+			}
+
+			err := tt.run(ctx, adapter)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("run() unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("run() error = %v, want %v", err, tt.wantErr)
+			}
+			var policyErr *pgxadapter.PolicyError
+			if !errors.As(err, &policyErr) {
+				t.Errorf("run() error = %T, want *PolicyError", err)
+			}
+
+			var count int
+			if err := db.QueryRow("SELECT COUNT(*) FROM " + tableName).Scan(&count); err != nil {
+				t.Fatalf("Failed to count rules: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("stored %d rules after rejected write, want 1", count)
+			}
+		})
+	}
+}
+
+func TestWithModelTextInvalid(t *testing.T) {
+	t.Parallel()
+
+	pool, err := pgxpool.New(context.Background(), getTestDBURL())
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	_, err = pgxadapter.NewAdapterWithPool(pool, pgxadapter.WithModelText("[request_definition]\nr = sub"))
+	if err == nil {
+		t.Fatal("NewAdapterWithPool() expected error for a model without a policy definition")
+	}
+}